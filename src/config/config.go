@@ -0,0 +1,352 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//EnvPrefix is the prefix of all environment variables read by Load, ex. SEARCHDEMO_DATA_DIR
+const EnvPrefix = "SEARCHDEMO_"
+
+//DefaultDataDir is used when no data directory is given by flags, environment variables or the config file
+const DefaultDataDir = "./data"
+
+//Config struct holds the settings of the application.
+//Files maps an entity label (ex. tickets) to the path of its data file; when a label has no entry, the file is looked up as <DataDir>/<label>.json
+//CSVListDelimiter splits the list columns (ex. tags) of CSV data files.
+//ValidationMode is one of strict, lenient or off, and ReportFormat is the format of the printed validation and reference reports, text or json.
+//SnapshotFile is where the built index is saved and reused on the next start; the snapshot is disabled when it is empty.
+//SchemaFile defines extra entities which are loaded as generic records, ex. groups; no extra entity is loaded when it is empty.
+//WatchInterval is how often the data files are polled for changes to reload, ex. 5s; the watch is disabled when it is empty.
+//FuzzyDistance is the number of typos tolerated by a fuzzy search and by the suggestions of a search without results.
+//PageSize is the number of results printed per page when a search does not give a limit, ex. 25; every result is printed at once when it is 0, the default.
+//Query is a query to run without the interactive prompts, ex. status:pending AND priority:high; it is only read from the flag and the environment variable.
+type Config struct {
+	DataDir          string            `json:"data_dir"`
+	Files            map[string]string `json:"files"`
+	CSVListDelimiter string            `json:"csv_list_delimiter"`
+	ValidationMode   string            `json:"validation_mode"`
+	ReportFormat     string            `json:"report_format"`
+	SnapshotFile     string            `json:"snapshot_file"`
+	WatchInterval    string            `json:"watch_interval"`
+	SchemaFile       string            `json:"schema_file"`
+	FuzzyDistance    string            `json:"fuzzy_distance"`
+	PageSize         string            `json:"page_size"`
+	Query            string            `json:"-"`
+}
+
+//Default returns the config used when nothing is configured; it reads the data files from ./data
+func Default() Config {
+	return Config{DataDir: DefaultDataDir, Files: map[string]string{}, CSVListDelimiter: ";", ValidationMode: "lenient", ReportFormat: "text", FuzzyDistance: "2", PageSize: "0"}
+}
+
+//FilePath returns the path of the data file for the given entity label
+func (c Config) FilePath(label string) string {
+	return c.EntityFilePath(label, label+".json")
+}
+
+//EntityFilePath returns the data file configured for the entity label, or the file with the given name in the data directory
+func (c Config) EntityFilePath(label, fileName string) string {
+	if path, ok := c.Files[label]; ok && len(path) != 0 {
+		return path
+	}
+	dataDir := c.DataDir
+	if len(dataDir) == 0 {
+		dataDir = DefaultDataDir
+	}
+	return filepath.Join(dataDir, fileName)
+}
+
+var (
+	entityFilesLock sync.RWMutex
+	entityFiles     = []string{}
+)
+
+//RegisterEntityFile adds the -<label> flag and the SEARCHDEMO_<LABEL>_FILE environment variable setting the data file of an entity.
+//The data package registers every entity it loads, ex. tickets.
+func RegisterEntityFile(label string) {
+	entityFilesLock.Lock()
+	defer entityFilesLock.Unlock()
+	for _, l := range entityFiles {
+		if l == label {
+			return
+		}
+	}
+	entityFiles = append(entityFiles, label)
+}
+
+//EntityFiles returns the registered entity labels
+func EntityFiles() []string {
+	entityFilesLock.RLock()
+	defer entityFilesLock.RUnlock()
+	return append([]string{}, entityFiles...)
+}
+
+//setting is a config value which can be given by both a command line flag and an environment variable (SEARCHDEMO_ + env)
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	value *string
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{flag: "data-dir", env: "DATA_DIR", usage: "directory containing the data files (default \"./data\")", value: &c.DataDir},
+		{flag: "csv-list-delimiter", env: "CSV_LIST_DELIMITER", usage: "delimiter splitting list columns such as tags in CSV data files (default \";\")", value: &c.CSVListDelimiter},
+		{flag: "validation", env: "VALIDATION", usage: "data validation mode: strict aborts the startup on failures, lenient reports them and loads anyway, off skips the validation (default \"lenient\")", value: &c.ValidationMode},
+		{flag: "snapshot", env: "SNAPSHOT_FILE", usage: "path of the index snapshot file, reused on the next start when the data files have not changed (disabled when empty)", value: &c.SnapshotFile},
+		{flag: "schema", env: "SCHEMA_FILE", usage: "path of a JSON schema file defining extra entities to search, ex. groups (disabled when empty)", value: &c.SchemaFile},
+		{flag: "watch-interval", env: "WATCH_INTERVAL", usage: "how often to poll the data files and reload them when changed, ex. 5s (disabled when empty)", value: &c.WatchInterval},
+		{flag: "fuzzy-distance", env: "FUZZY_DISTANCE", usage: "number of typos tolerated by a fuzzy search such as fransisca~ and by the suggestions, 0 to 3 (default \"2\")", value: &c.FuzzyDistance},
+		{flag: "page-size", env: "PAGE_SIZE", usage: "number of results per page when a search gives no limit, ex. 25; 0 prints every result at once without paging (default \"0\")", value: &c.PageSize},
+		{flag: "query", env: "QUERY", usage: "run the query, ex. \"status:pending AND priority:high AND NOT tags:ohio\", print the results as JSON and exit", value: &c.Query},
+		{flag: "validation-report", env: "VALIDATION_REPORT", usage: "former name of -report-format, kept for compatibility", value: &c.ReportFormat},
+		{flag: "report-format", env: "REPORT_FORMAT", usage: "format of the validation and check-refs reports: text or json (default \"text\")", value: &c.ReportFormat},
+	}
+}
+
+//Load func builds the config from the command line args, environment variables and an optional JSON config file.
+//The precedence from lowest to highest is: defaults, config file, environment variables, command line flags.
+//The config file is given by the -config flag or the SEARCHDEMO_CONFIG environment variable; relative paths inside it are resolved against the config file's directory.
+func Load(args []string, lookupEnv func(key string) (string, bool)) (cfg Config, err error) {
+	cfg = Default()
+	settings := cfg.settings()
+	for _, label := range EntityFiles() {
+		settings = append(settings, setting{flag: label, env: strings.ToUpper(label) + "_FILE", usage: fmt.Sprintf("path of the %s data file, overrides -data-dir for this file", label)})
+	}
+
+	fs := flag.NewFlagSet("searchDemo", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path of a JSON config file")
+	flagValues := make([]*string, len(settings))
+	for i, st := range settings {
+		flagValues[i] = fs.String(st.flag, "", st.usage)
+	}
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	if !setFlags["config"] {
+		*configFile, _ = lookupEnv(EnvPrefix + "CONFIG")
+	}
+	if len(*configFile) != 0 {
+		err = cfg.applyFile(*configFile)
+		if err != nil {
+			return
+		}
+	}
+
+	for i, st := range settings {
+		value, ok := lookupEnv(EnvPrefix + st.env)
+		ok = ok && len(value) != 0
+		if setFlags[st.flag] {
+			value, ok = *flagValues[i], true
+		}
+		if !ok {
+			continue
+		}
+		if st.value == nil {
+			//Settings without a value pointer are the per-entity data files
+			cfg.Files[st.flag] = value
+			continue
+		}
+		*st.value = value
+	}
+	err = cfg.validate()
+	return
+}
+
+//LookupSchemaFile func finds the schema file in the command line args, the environment variables and the config file, with the same precedence as Load.
+//The entities of the schema add their own file flags, ex. -groups, so the schema is registered before Load builds the flags; the other flags are skipped here.
+func LookupSchemaFile(args []string, lookupEnv func(key string) (string, bool)) (schemaFile string, err error) {
+	flagValues := scanFlags(args, "schema", "config")
+	if value, ok := flagValues["schema"]; ok {
+		return value, nil
+	}
+	if value, ok := lookupEnv(EnvPrefix + "SCHEMA_FILE"); ok && len(value) != 0 {
+		return value, nil
+	}
+	configFile, ok := flagValues["config"]
+	if !ok {
+		configFile, _ = lookupEnv(EnvPrefix + "CONFIG")
+	}
+	if len(configFile) == 0 {
+		return
+	}
+	cfg := Default()
+	err = cfg.applyFile(configFile)
+	return cfg.SchemaFile, err
+}
+
+//scanFlags returns the values of the named flags in the args, ex. -schema groups.json or --schema=groups.json, without failing on the flags it does not know.
+//Every flag but -h takes a value, so the arg after a flag without = is its value; the scan stops at the first arg which is not a flag, as flag.Parse does.
+func scanFlags(args []string, names ...string) map[string]string {
+	values := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		value := ""
+		hasValue := false
+		if equals := strings.Index(name, "="); equals >= 0 {
+			name, value, hasValue = name[:equals], name[equals+1:], true
+		}
+		if name == "h" || name == "help" {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		for _, n := range names {
+			if n == name {
+				values[name] = value
+			}
+		}
+	}
+	return values
+}
+
+//validate checks the settings which only accept a fixed set of values
+func (c Config) validate() error {
+	choices := []struct {
+		name    string
+		value   string
+		allowed []string
+	}{
+		{name: "validation", value: c.ValidationMode, allowed: []string{"strict", "lenient", "off"}},
+		{name: "report-format", value: c.ReportFormat, allowed: []string{"text", "json"}},
+	}
+	for _, choice := range choices {
+		isAllowed := false
+		for _, allowed := range choice.allowed {
+			isAllowed = isAllowed || choice.value == allowed
+		}
+		if !isAllowed {
+			return fmt.Errorf("invalid %s value %q, expected one of: %s", choice.name, choice.value, strings.Join(choice.allowed, ", "))
+		}
+	}
+	_, err := c.WatchDuration()
+	if err != nil {
+		return err
+	}
+	_, err = c.MaxFuzzyDistance()
+	if err != nil {
+		return err
+	}
+	_, err = c.ResultsPerPage()
+	return err
+}
+
+//WatchDuration returns the parsed WatchInterval, 0 when the watch is disabled
+func (c Config) WatchDuration() (time.Duration, error) {
+	if len(c.WatchInterval) == 0 {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.WatchInterval)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid watch-interval value %q, expected a positive duration such as 5s", c.WatchInterval)
+	}
+	return d, nil
+}
+
+//MaxFuzzyDistance returns the parsed FuzzyDistance
+func (c Config) MaxFuzzyDistance() (int, error) {
+	distance, err := strconv.Atoi(strings.TrimSpace(c.FuzzyDistance))
+	if err != nil || distance < 0 || distance > 3 {
+		return 0, fmt.Errorf("invalid fuzzy-distance value %q, expected a number of typos from 0 to 3", c.FuzzyDistance)
+	}
+	return distance, nil
+}
+
+//ResultsPerPage returns the parsed PageSize, 0 when the results are not paged by default
+func (c Config) ResultsPerPage() (int, error) {
+	size, err := strconv.Atoi(strings.TrimSpace(c.PageSize))
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid page-size value %q, expected a number of results per page, or 0 to print every result", c.PageSize)
+	}
+	return size, nil
+}
+
+//fileConfig is the content of a JSON config file; a key the file does not set is nil, so the setting keeps its default
+type fileConfig struct {
+	DataDir          *string           `json:"data_dir"`
+	Files            map[string]string `json:"files"`
+	CSVListDelimiter *string           `json:"csv_list_delimiter"`
+	ValidationMode   *string           `json:"validation_mode"`
+	ReportFormat     *string           `json:"report_format"`
+	ValidationReport *string           `json:"validation_report"`
+	SnapshotFile     *string           `json:"snapshot_file"`
+	WatchInterval    *string           `json:"watch_interval"`
+	SchemaFile       *string           `json:"schema_file"`
+	FuzzyDistance    *string           `json:"fuzzy_distance"`
+	PageSize         *string           `json:"page_size"`
+}
+
+//applyFile reads the JSON config file over the defaults; the paths set by the file are resolved against its directory, even when they equal a default such as ./data
+func (c *Config) applyFile(path string) (err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file %s failed: %v", path, err)
+	}
+	var file fileConfig
+	err = json.Unmarshal(content, &file)
+	if err != nil {
+		return fmt.Errorf("parse config file %s failed: %v", path, err)
+	}
+	baseDir := filepath.Dir(path)
+	keys := []struct {
+		value   *string
+		setting *string
+		isPath  bool
+	}{
+		{value: file.DataDir, setting: &c.DataDir, isPath: true},
+		{value: file.CSVListDelimiter, setting: &c.CSVListDelimiter},
+		{value: file.ValidationMode, setting: &c.ValidationMode},
+		//validation_report is the former name of report_format, kept so older config files still work
+		{value: file.ValidationReport, setting: &c.ReportFormat},
+		{value: file.ReportFormat, setting: &c.ReportFormat},
+		{value: file.SnapshotFile, setting: &c.SnapshotFile, isPath: true},
+		{value: file.WatchInterval, setting: &c.WatchInterval},
+		{value: file.SchemaFile, setting: &c.SchemaFile, isPath: true},
+		{value: file.FuzzyDistance, setting: &c.FuzzyDistance},
+		{value: file.PageSize, setting: &c.PageSize},
+	}
+	for _, key := range keys {
+		if key.value == nil {
+			continue
+		}
+		*key.setting = *key.value
+		if key.isPath {
+			*key.setting = resolvePath(baseDir, *key.setting)
+		}
+	}
+	if c.Files == nil {
+		c.Files = map[string]string{}
+	}
+	for label, filePath := range file.Files {
+		c.Files[label] = resolvePath(baseDir, filePath)
+	}
+	return
+}
+
+func resolvePath(baseDir, path string) string {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
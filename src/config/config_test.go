@@ -0,0 +1,185 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/config"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	for _, label := range []string{"tickets", "users", "organizations"} {
+		config.RegisterEntityFile(label)
+	}
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(configFile, []byte(`{"data_dir": "staging", "files": {"users": "/exports/users.json"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultDirConfigFile := filepath.Join(dir, "default_dir.json")
+	err = ioutil.WriteFile(defaultDirConfigFile, []byte(`{"data_dir": "./data"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formerKeyConfigFile := filepath.Join(dir, "former_key.json")
+	err = ioutil.WriteFile(formerKeyConfigFile, []byte(`{"validation_report": "json"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		args                []string
+		env                 map[string]string
+		expectedTicketsPath string
+		expectedUsersPath   string
+		expectedOrgsPath    string
+		expectedFormat      string
+		expectedHasError    bool
+	}{
+		"no settings should read from ./data": {
+			expectedTicketsPath: "data/tickets.json",
+			expectedUsersPath:   "data/users.json",
+			expectedOrgsPath:    "data/organizations.json",
+		},
+		"config file paths are resolved against the config file directory": {
+			args:                []string{"-config", configFile},
+			expectedTicketsPath: filepath.Join(dir, "staging", "tickets.json"),
+			expectedUsersPath:   "/exports/users.json",
+			expectedOrgsPath:    filepath.Join(dir, "staging", "organizations.json"),
+		},
+		"config file data directory equal to the default is resolved against the config file directory": {
+			args:                []string{"-config", defaultDirConfigFile},
+			expectedTicketsPath: filepath.Join(dir, "data", "tickets.json"),
+			expectedUsersPath:   filepath.Join(dir, "data", "users.json"),
+			expectedOrgsPath:    filepath.Join(dir, "data", "organizations.json"),
+		},
+		"environment variables override the config file": {
+			env:                 map[string]string{"SEARCHDEMO_CONFIG": configFile, "SEARCHDEMO_DATA_DIR": "prod", "SEARCHDEMO_USERS_FILE": "fixtures/users.json"},
+			expectedTicketsPath: "prod/tickets.json",
+			expectedUsersPath:   "fixtures/users.json",
+			expectedOrgsPath:    "prod/organizations.json",
+		},
+		"flags override environment variables": {
+			args:                []string{"-data-dir", "flagdir", "-organizations", "orgs.json"},
+			env:                 map[string]string{"SEARCHDEMO_DATA_DIR": "prod", "SEARCHDEMO_ORGANIZATIONS_FILE": "env_orgs.json"},
+			expectedTicketsPath: "flagdir/tickets.json",
+			expectedUsersPath:   "flagdir/users.json",
+			expectedOrgsPath:    "orgs.json",
+		},
+		"former validation report key sets the report format": {
+			args:                []string{"-config", formerKeyConfigFile},
+			expectedTicketsPath: "data/tickets.json",
+			expectedUsersPath:   "data/users.json",
+			expectedOrgsPath:    "data/organizations.json",
+			expectedFormat:      "json",
+		},
+		"former validation report flag sets the report format": {
+			args:                []string{"-validation-report", "json"},
+			expectedTicketsPath: "data/tickets.json",
+			expectedUsersPath:   "data/users.json",
+			expectedOrgsPath:    "data/organizations.json",
+			expectedFormat:      "json",
+		},
+		"invalid fuzzy distance should return an error": {
+			args:             []string{"-fuzzy-distance", "4"},
+			expectedHasError: true,
+		},
+		"negative page size should return an error": {
+			args:             []string{"-page-size", "-5"},
+			expectedHasError: true,
+		},
+		"missing config file should return an error": {
+			args:             []string{"-config", filepath.Join(dir, "missing.json")},
+			expectedHasError: true,
+		},
+	}
+	for tc, tp := range testCases {
+		lookupEnv := func(key string) (string, bool) {
+			v, ok := tp.env[key]
+			return v, ok
+		}
+		cfg, err := config.Load(tp.args, lookupEnv)
+		if tp.expectedHasError {
+			if err == nil {
+				t.Errorf("For test case <%s>, Expected there is an error, but actually not", tc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		for label, expectedPath := range map[string]string{"tickets": tp.expectedTicketsPath, "users": tp.expectedUsersPath, "organizations": tp.expectedOrgsPath} {
+			if actualPath := cfg.FilePath(label); actualPath != expectedPath {
+				t.Errorf("For test case <%s>, Expected %s path is <%s>, but actual path is <%s>", tc, label, expectedPath, actualPath)
+			}
+		}
+		if len(tp.expectedFormat) != 0 && cfg.ReportFormat != tp.expectedFormat {
+			t.Errorf("For test case <%s>, Expected report format is <%s>, but actual format is <%s>", tc, tp.expectedFormat, cfg.ReportFormat)
+		}
+	}
+}
+
+func TestLookupSchemaFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(configFile, []byte(`{"schema_file": "schema.json"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		args               []string
+		env                map[string]string
+		expectedSchemaFile string
+	}{
+		"no schema": {
+			args: []string{"-data-dir", "data"},
+		},
+		"schema flag after a flag of a schema entity": {
+			args:               []string{"-groups", "groups.json", "-schema", "schema.json"},
+			expectedSchemaFile: "schema.json",
+		},
+		"schema flag with an equal sign": {
+			args:               []string{"--schema=schema.json", "-groups", "groups.json"},
+			expectedSchemaFile: "schema.json",
+		},
+		"flag value named like the schema flag is not the flag": {
+			args: []string{"-query", "-schema", "-data-dir", "data"},
+		},
+		"environment variable": {
+			env:                map[string]string{"SEARCHDEMO_SCHEMA_FILE": "env_schema.json"},
+			expectedSchemaFile: "env_schema.json",
+		},
+		"config file schema is resolved against the config file directory": {
+			args:               []string{"-config", configFile, "-groups", "groups.json"},
+			expectedSchemaFile: filepath.Join(dir, "schema.json"),
+		},
+		"flag overrides the environment variable and the config file": {
+			args:               []string{"-schema", "flag_schema.json"},
+			env:                map[string]string{"SEARCHDEMO_CONFIG": configFile, "SEARCHDEMO_SCHEMA_FILE": "env_schema.json"},
+			expectedSchemaFile: "flag_schema.json",
+		},
+	}
+	for tc, tp := range testCases {
+		lookupEnv := func(key string) (string, bool) {
+			v, ok := tp.env[key]
+			return v, ok
+		}
+		schemaFile, err := config.LookupSchemaFile(tp.args, lookupEnv)
+		if err != nil || schemaFile != tp.expectedSchemaFile {
+			t.Errorf("For test case <%s>, Expected schema file is <%s>, but actual schema file is <%s> and error is <%v>", tc, tp.expectedSchemaFile, schemaFile, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Decompressor describes a compression codec for data files. A file is decompressed when it starts with Magic, or when its name ends with one of Extensions (ex. ".gz").
+type Decompressor struct {
+	Name       string
+	Extensions []string
+	Magic      []byte
+	NewReader  func(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	decompressorsLock sync.RWMutex
+	decompressors     = []Decompressor{}
+)
+
+func init() {
+	RegisterDecompressor(Decompressor{
+		Name:       "gzip",
+		Extensions: []string{".gz", ".gzip"},
+		Magic:      []byte{0x1f, 0x8b},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	})
+}
+
+//RegisterDecompressor adds a codec to the registry used by OpenFile. gzip is registered by default; other codecs such as zstd
+//(extension ".zst", magic 28 b5 2f fd) can be registered by wrapping a third party reader.
+func RegisterDecompressor(decompressor Decompressor) {
+	decompressorsLock.Lock()
+	defer decompressorsLock.Unlock()
+	decompressors = append(decompressors, decompressor)
+}
+
+//OpenFile opens a data file and transparently decompresses it when its magic bytes or extension match a registered Decompressor
+func OpenFile(filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	decompressor, ok := detectDecompressor(filePath, reader)
+	if !ok {
+		return &dataFile{Reader: reader, closers: []io.Closer{file}}, nil
+	}
+	decompressed, err := decompressor.NewReader(reader)
+	if err != nil {
+		file.Close()
+		return nil, &os.PathError{Op: "decompress " + decompressor.Name, Path: filePath, Err: err}
+	}
+	return &dataFile{Reader: decompressed, closers: []io.Closer{decompressed, file}}, nil
+}
+
+//readFile reads the whole decompressed content of a data file
+func readFile(filePath string) ([]byte, error) {
+	file, err := OpenFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func detectDecompressor(filePath string, reader *bufio.Reader) (decompressor Decompressor, ok bool) {
+	decompressorsLock.RLock()
+	defer decompressorsLock.RUnlock()
+	for _, d := range decompressors {
+		if len(d.Magic) == 0 {
+			continue
+		}
+		head, _ := reader.Peek(len(d.Magic))
+		if bytes.Equal(head, d.Magic) {
+			return d, true
+		}
+	}
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for _, d := range decompressors {
+		for _, e := range d.Extensions {
+			if ext == e {
+				return d, true
+			}
+		}
+	}
+	return
+}
+
+//trimCompressionExt removes a registered compression extension from the file name, so tickets.csv.gz is handled as tickets.csv
+func trimCompressionExt(filePath string) string {
+	decompressorsLock.RLock()
+	defer decompressorsLock.RUnlock()
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for _, d := range decompressors {
+		for _, e := range d.Extensions {
+			if ext == e {
+				return filePath[:len(filePath)-len(ext)]
+			}
+		}
+	}
+	return filePath
+}
+
+//compressedPaths returns the file path followed by the path with each registered compression extension appended, ex. tickets.json, tickets.json.gz
+func compressedPaths(filePath string) []string {
+	decompressorsLock.RLock()
+	defer decompressorsLock.RUnlock()
+	paths := []string{filePath}
+	for _, d := range decompressors {
+		for _, e := range d.Extensions {
+			paths = append(paths, filePath+e)
+		}
+	}
+	return paths
+}
+
+//dataFile closes the decompressor, if any, before the underlying file
+type dataFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *dataFile) Close() (err error) {
+	for _, closer := range f.closers {
+		e := closer.Close()
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	return
+}
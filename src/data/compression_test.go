@@ -0,0 +1,57 @@
+package data_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/config"
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestLoadCompressedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compression")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		//found by appending .gz to the default tickets.json path
+		"tickets.json.gz": `[{"_id": "t1"}, {"_id": "t2"}]`,
+		//detected by the gzip magic bytes, as the file name has no compression extension
+		"users.json": `{"_id": 1}` + "\n" + `{"_id": 2}` + "\n" + `{"_id": 3}`,
+		//the .gz extension is trimmed before choosing the CSV serializer
+		"orgs.csv.gz": "_id,domain_names\n101,kage.com;ecratic.com\n",
+	}
+	for name, content := range files {
+		buffer := &bytes.Buffer{}
+		writer := gzip.NewWriter(buffer)
+		writer.Write([]byte(content))
+		writer.Close()
+		err = ioutil.WriteFile(filepath.Join(dir, name), buffer.Bytes(), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serializer := data.NewExtensionSerializer(data.NewStreamSerializer(), map[string]data.StreamSerializer{
+		".csv": data.NewCSVSerializer(";"),
+	})
+	cfg := config.Config{DataDir: dir, Files: map[string]string{"organizations": filepath.Join(dir, "orgs.csv.gz")}}
+	dataset, err := data.NewService(serializer, cfg).LoadFile()
+	if err != nil {
+		t.Fatalf("Expected there is no error, but actual error is <%v>", err)
+	}
+	tickets, users, organizations := dataset.Tickets(), dataset.Users(), dataset.Organizations()
+	if len(tickets) != 2 || tickets[1].ID != "t2" {
+		t.Errorf("Expected 2 tickets loaded from tickets.json.gz, but actual tickets are <%+v>", tickets)
+	}
+	if len(users) != 3 || users[2].ID != 3 {
+		t.Errorf("Expected 3 users loaded from the gzip users.json, but actual users are <%+v>", users)
+	}
+	if len(organizations) != 1 || len(organizations[0].DomainNames) != 2 {
+		t.Errorf("Expected 1 organization with 2 domain names loaded from orgs.csv.gz, but actual organizations are <%+v>", organizations)
+	}
+}
@@ -1,23 +1,24 @@
 package data
 
-//Ticket struct for unmarshal ticket json object
+//Ticket struct for unmarshal ticket json object; missing holds the fields which are not in the json object, or null
 type Ticket struct {
 	ID             string   `json:"_id"`
 	URL            string   `json:"url"`
 	ExternalID     string   `json:"external_id"`
-	CreatedAt      string   `json:"created_at"`
+	CreatedAt      string   `json:"created_at" search:"time"`
 	Type           string   `json:"type"`
-	Subject        string   `json:"subject"`
-	Description    string   `json:"description"`
-	Priority       string   `json:"priority"`
+	Subject        string   `json:"subject" search:"text" boost:"3"`
+	Description    string   `json:"description" search:"text"`
+	Priority       string   `json:"priority" order:"low,normal,high,urgent"`
 	Status         string   `json:"status"`
 	SubmitterID    int      `json:"submitter_id"`
 	AssigneeID     int      `json:"assignee_id"`
 	OrganizationID int      `json:"organization_id"`
 	Tags           []string `json:"tags"`
 	HasIncidents   bool     `json:"has_incidents"`
-	DueAt          string   `json:"due_at"`
+	DueAt          string   `json:"due_at" search:"time"`
 	Via            string   `json:"via"`
+	missing        fieldSet
 }
 
 //User struct for unmarshal user json object
@@ -25,22 +26,23 @@ type User struct {
 	ID             int      `json:"_id"`
 	URL            string   `json:"url"`
 	ExternalID     string   `json:"external_id"`
-	Name           string   `json:"name"`
+	Name           string   `json:"name" search:"text" boost:"3"`
 	Alias          string   `json:"alias"`
-	CreatedAt      string   `json:"created_at"`
+	CreatedAt      string   `json:"created_at" search:"time"`
 	Active         bool     `json:"active"`
 	Verified       bool     `json:"verified"`
 	Shared         bool     `json:"shared"`
 	Locale         string   `json:"locale"`
 	TimeZone       string   `json:"timezone"`
-	LastLoginAt    string   `json:"last_login_at"`
+	LastLoginAt    string   `json:"last_login_at" search:"time"`
 	Email          string   `json:"email"`
 	Phone          string   `json:"phone"`
-	Signature      string   `json:"signature"`
+	Signature      string   `json:"signature" search:"text"`
 	OrganizationID int      `json:"organization_id"`
 	Tags           []string `json:"tags"`
 	Suspended      bool     `json:"suspended"`
 	Role           string   `json:"role"`
+	missing        fieldSet
 }
 
 //Organization struct for unmarshal organization json object
@@ -48,12 +50,13 @@ type Organization struct {
 	ID            int      `json:"_id"`
 	URL           string   `json:"url"`
 	ExternalID    string   `json:"external_id"`
-	Name          string   `json:"name"`
+	Name          string   `json:"name" search:"text" boost:"3"`
 	DomainNames   []string `json:"domain_names"`
-	CreatedAt     string   `json:"created_at"`
-	Details       string   `json:"details"`
+	CreatedAt     string   `json:"created_at" search:"time"`
+	Details       string   `json:"details" search:"text"`
 	SharedTickets bool     `json:"shared_tickets"`
 	Tags          []string `json:"tags"`
+	missing       fieldSet
 }
 
 type TicketForDisplay struct {
@@ -0,0 +1,284 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type csvSerializer struct {
+	ListDelimiter string
+}
+
+//NewCSVSerializer returns a StreamSerializer for spreadsheet CSV exports. The header row is matched case-insensitively against the json tags of the record struct (ex. _id, submitter_id),
+//int and bool columns are converted, and list columns such as tags are split on listDelimiter. Columns without a matching field are ignored.
+func NewCSVSerializer(listDelimiter string) StreamSerializer {
+	if len(listDelimiter) == 0 {
+		listDelimiter = ";"
+	}
+	return &csvSerializer{ListDelimiter: listDelimiter}
+}
+
+func (s *csvSerializer) ReadFile(filePath string) ([]byte, error) {
+	return readFile(filePath)
+}
+
+func (s *csvSerializer) Unmarshal(dataForSerialize []byte, v interface{}) error {
+	newRecord, handleRecord, err := sliceAppender(v)
+	if err != nil {
+		return err
+	}
+	return s.decodeRows(bytes.NewReader(dataForSerialize), newRecord, handleRecord)
+}
+
+func (s *csvSerializer) Stream(filePath string, newRecord func() interface{}, handleRecord func(record interface{}) error) error {
+	file, err := OpenFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.decodeRows(file, newRecord, handleRecord)
+}
+
+//csvColumn links a CSV column to the struct field it is converted into, or to the field name of a schema Record; fieldName is also the Go name of the struct field
+type csvColumn struct {
+	header     string
+	fieldIndex int
+	fieldName  string
+	fieldType  reflect.Type
+	kind       reflect.Kind
+}
+
+func (s *csvSerializer) decodeRows(r io.Reader, newRecord func() interface{}, handleRecord func(record interface{}) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return csvPositionError(err, -1)
+	}
+	sample := newRecord()
+	var columns []*csvColumn
+	entity, isSchemaRecord := schemaEntity(sample)
+	if isSchemaRecord {
+		columns = mapRecordColumns(header, entity)
+	} else {
+		columns = mapCSVColumns(header, reflect.TypeOf(sample).Elem())
+	}
+	absent := absentColumns(sample, columns)
+
+	for record := 0; ; record++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return csvPositionError(err, record)
+		}
+		v := newRecord()
+		target := reflect.ValueOf(v).Elem()
+		if isSchemaRecord {
+			v.(*Record).Values = zeroValues(entity)
+		}
+		missing := fieldSet{}
+		for fieldName := range absent {
+			missing[fieldName] = true
+		}
+		for i, value := range row {
+			column := columns[i]
+			if column == nil {
+				continue
+			}
+			//A CSV cell has no null, so an empty number or bool is missing, while an empty string or list is blank
+			if len(strings.TrimSpace(value)) == 0 && column.kind != reflect.String && column.kind != reflect.Slice {
+				missing[column.fieldName] = true
+			}
+			if isSchemaRecord {
+				field := reflect.New(column.fieldType).Elem()
+				err = s.setField(field, column.kind, value)
+				v.(*Record).Values[column.fieldName] = field.Interface()
+			} else {
+				err = s.setField(target.Field(column.fieldIndex), column.kind, value)
+			}
+			if err != nil {
+				line, columnNumber := reader.FieldPos(i)
+				return &PositionError{Line: line, Column: columnNumber, Record: record, Err: fmt.Errorf("column %q: %v", column.header, err)}
+			}
+		}
+		r, ok := v.(presenceRecord)
+		if ok {
+			r.setMissingFields(missing)
+		}
+		err = handleRecord(v)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//absentColumns returns the fields of the record without a column in the CSV header; they are missing from every record of the file
+func absentColumns(sample interface{}, columns []*csvColumn) fieldSet {
+	names := []string{}
+	entity, isSchemaRecord := schemaEntity(sample)
+	if isSchemaRecord {
+		for _, field := range entity.Fields {
+			names = append(names, field.Name)
+		}
+	} else {
+		recordType := reflect.TypeOf(sample).Elem()
+		for i := 0; i < recordType.NumField(); i++ {
+			tag := strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0]
+			if len(tag) != 0 && tag != "-" {
+				names = append(names, recordType.Field(i).Name)
+			}
+		}
+	}
+	absent := fieldSet{}
+	for _, name := range names {
+		absent[name] = true
+	}
+	for _, column := range columns {
+		if column != nil {
+			delete(absent, column.fieldName)
+		}
+	}
+	return absent
+}
+
+//mapCSVColumns returns the matched struct field for each header column, or nil for the columns without a matching json tag
+func mapCSVColumns(header []string, recordType reflect.Type) []*csvColumn {
+	fieldsByTag := map[string]int{}
+	for i := 0; i < recordType.NumField(); i++ {
+		tag := strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0]
+		if len(tag) != 0 && tag != "-" {
+			fieldsByTag[strings.ToLower(tag)] = i
+		}
+	}
+	columns := make([]*csvColumn, len(header))
+	for i, name := range header {
+		//Spreadsheet tools often prefix the first header with a UTF-8 byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		fieldIndex, ok := fieldsByTag[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		columns[i] = &csvColumn{header: name, fieldIndex: fieldIndex, fieldName: recordType.Field(fieldIndex).Name, kind: recordType.Field(fieldIndex).Type.Kind()}
+	}
+	return columns
+}
+
+//mapRecordColumns returns the schema field for each header column, or nil for the columns which are not defined in the schema
+func mapRecordColumns(header []string, entity Entity) []*csvColumn {
+	fieldsByName := map[string]FieldDefinition{}
+	for _, field := range entity.Fields {
+		fieldsByName[strings.ToLower(field.Name)] = field
+	}
+	columns := make([]*csvColumn, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		field, ok := fieldsByName[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		fieldType := reflect.TypeOf(zeroValue(field.Type))
+		columns[i] = &csvColumn{header: name, fieldName: field.Name, fieldType: fieldType, kind: fieldType.Kind()}
+	}
+	return columns
+}
+
+//setField converts a CSV cell into the field's type; an empty cell leaves the field as its zero value
+func (s *csvSerializer) setField(field reflect.Value, kind reflect.Kind, value string) error {
+	trimmed := strings.TrimSpace(value)
+	switch kind {
+	case reflect.Int:
+		if len(trimmed) == 0 {
+			return nil
+		}
+		i, err := strconv.Atoi(trimmed)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid int", value)
+		}
+		field.SetInt(int64(i))
+	case reflect.Bool:
+		if len(trimmed) == 0 {
+			return nil
+		}
+		b, err := strconv.ParseBool(strings.ToLower(trimmed))
+		if err != nil {
+			return fmt.Errorf("%q is not a valid bool", value)
+		}
+		field.SetBool(b)
+	case reflect.Float64:
+		if len(trimmed) == 0 {
+			return nil
+		}
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid float", value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if len(trimmed) == 0 {
+			return nil
+		}
+		list := []string{}
+		for _, element := range strings.Split(value, s.ListDelimiter) {
+			element = strings.TrimSpace(element)
+			if len(element) != 0 {
+				list = append(list, element)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("unsupported field type %s", kind)
+	}
+	return nil
+}
+
+func csvPositionError(err error, record int) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &PositionError{Line: parseErr.Line, Column: parseErr.Column, Record: record, Err: parseErr.Err}
+	}
+	return err
+}
+
+type extensionSerializer struct {
+	Default     StreamSerializer
+	ByExtension map[string]StreamSerializer
+}
+
+//NewExtensionSerializer returns a StreamSerializer which streams each file with the serializer registered for its extension (ex. ".csv"), falling back to defaultSerializer.
+//As Unmarshal does not know the file name, it always uses defaultSerializer.
+func NewExtensionSerializer(defaultSerializer StreamSerializer, byExtension map[string]StreamSerializer) StreamSerializer {
+	return &extensionSerializer{Default: defaultSerializer, ByExtension: byExtension}
+}
+
+func (s *extensionSerializer) ReadFile(filePath string) ([]byte, error) {
+	return s.serializerFor(filePath).ReadFile(filePath)
+}
+
+func (s *extensionSerializer) Unmarshal(dataForSerialize []byte, v interface{}) error {
+	return s.Default.Unmarshal(dataForSerialize, v)
+}
+
+func (s *extensionSerializer) Stream(filePath string, newRecord func() interface{}, handleRecord func(record interface{}) error) error {
+	return s.serializerFor(filePath).Stream(filePath, newRecord, handleRecord)
+}
+
+func (s *extensionSerializer) serializerFor(filePath string) StreamSerializer {
+	serializer, ok := s.ByExtension[strings.ToLower(filepath.Ext(trimCompressionExt(filePath)))]
+	if !ok {
+		return s.Default
+	}
+	return serializer
+}
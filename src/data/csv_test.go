@@ -0,0 +1,85 @@
+package data_test
+
+import (
+	"errors"
+	"reflect"
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestCSVSerializer(t *testing.T) {
+	testCases := map[string]struct {
+		content              string
+		expectedTickets      []*data.Ticket
+		expectedMissing      []map[string]bool
+		expectedHasError     bool
+		expectedErrorMessage string
+		expectedErrorRecord  int
+		expectedErrorLine    int
+		expectedErrorColumn  int
+	}{
+		"header columns are mapped to the json tags": {
+			content: "\ufeff_id,Subject,submitter_id,has_incidents,tags,unknown\n" +
+				"t1,\"A Catastrophe, in Korea\",38,TRUE,Ohio| Idaho ,x\n" +
+				"t2,,,,,\n",
+			expectedTickets: []*data.Ticket{
+				{ID: "t1", Subject: "A Catastrophe, in Korea", SubmitterID: 38, HasIncidents: true, Tags: []string{"Ohio", "Idaho"}},
+				{ID: "t2"},
+			},
+			//A column missing from the header or an empty number cell is missing, an empty string cell is blank
+			expectedMissing: []map[string]bool{
+				{"SubmitterID": false, "AssigneeID": true, "Subject": false},
+				{"SubmitterID": true, "HasIncidents": true, "Subject": false},
+			},
+		},
+		"invalid int reports the row and column": {
+			content:              "_id,submitter_id\nt1,38\nt2,abc\n",
+			expectedHasError:     true,
+			expectedErrorMessage: "record index 1 at line 3, column 4: column \"submitter_id\": \"abc\" is not a valid int",
+			expectedErrorRecord:  1,
+			expectedErrorLine:    3,
+			expectedErrorColumn:  4,
+		},
+		"invalid bool reports the row and column": {
+			content:              "_id,has_incidents\nt1,maybe\n",
+			expectedHasError:     true,
+			expectedErrorMessage: "record index 0 at line 2, column 4: column \"has_incidents\": \"maybe\" is not a valid bool",
+			expectedErrorRecord:  0,
+			expectedErrorLine:    2,
+			expectedErrorColumn:  4,
+		},
+	}
+	for tc, tp := range testCases {
+		tickets := []*data.Ticket{}
+		err := data.NewCSVSerializer("|").Unmarshal([]byte(tp.content), &tickets)
+		if !tp.expectedHasError {
+			if err != nil {
+				t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+				continue
+			}
+			for i, expectedMissing := range tp.expectedMissing {
+				for fieldName, isMissing := range expectedMissing {
+					if data.IsMissing(tickets[i], fieldName) != isMissing {
+						t.Errorf("For test case <%s>, Expected field %s of ticket %d is missing <%t>, but actually not", tc, fieldName, i, isMissing)
+					}
+				}
+			}
+			data.ClearMissing(tickets)
+			if !reflect.DeepEqual(tp.expectedTickets, tickets) {
+				t.Errorf("For test case <%s>, Expected tickets are <%+v>, but actual tickets are <%+v>", tc, tp.expectedTickets, tickets)
+			}
+			continue
+		}
+		var positionErr *data.PositionError
+		if !errors.As(err, &positionErr) {
+			t.Errorf("For test case <%s>, Expected a PositionError, but actual error is <%v>", tc, err)
+			continue
+		}
+		if err.Error() != tp.expectedErrorMessage {
+			t.Errorf("For test case <%s>, Expected error message is <%s>, but actual message is <%s>", tc, tp.expectedErrorMessage, err.Error())
+		}
+		if positionErr.Record != tp.expectedErrorRecord || positionErr.Line != tp.expectedErrorLine || positionErr.Column != tp.expectedErrorColumn {
+			t.Errorf("For test case <%s>, Expected error at record <%d> line <%d> column <%d>, but actual error is <%v>", tc, tp.expectedErrorRecord, tp.expectedErrorLine, tp.expectedErrorColumn, err)
+		}
+	}
+}
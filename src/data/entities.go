@@ -0,0 +1,94 @@
+package data
+
+func init() {
+	RegisterEntity(Entity{
+		Name:      "tickets",
+		NewRecord: func() interface{} { return &Ticket{} },
+		IDField:   "id",
+		Relations: []Relation{
+			{Name: "submitter", Field: "submitterid", Target: "users", TargetField: "id"},
+			{Name: "assignee", Field: "assigneeid", Target: "users", TargetField: "id"},
+			{Name: "organization", Field: "organizationid", Target: "organizations", TargetField: "id"},
+		},
+		Display: displayTicket,
+		Facets:  []string{"status", "priority", "type", "via", "tags"},
+	})
+	RegisterEntity(Entity{
+		Name:      "users",
+		NewRecord: func() interface{} { return &User{} },
+		IDField:   "id",
+		Relations: []Relation{
+			{Name: "organization", Field: "organizationid", Target: "organizations", TargetField: "id"},
+			{Name: "submittedTickets", Field: "id", Target: "tickets", TargetField: "submitterid"},
+			{Name: "assignedTickets", Field: "id", Target: "tickets", TargetField: "assigneeid"},
+		},
+		Display: displayUser,
+		Facets:  []string{"role", "locale", "active"},
+	})
+	RegisterEntity(Entity{
+		Name:      "organizations",
+		NewRecord: func() interface{} { return &Organization{} },
+		IDField:   "id",
+		Relations: []Relation{
+			{Name: "tickets", Field: "id", Target: "tickets", TargetField: "organizationid"},
+			{Name: "users", Field: "id", Target: "users", TargetField: "organizationid"},
+		},
+		Display: displayOrganization,
+		Facets:  []string{"tags"},
+	})
+}
+
+//displayTicket shows the ticket with the names of its submitter, assignee and organization
+func displayTicket(record interface{}, linked map[string][]interface{}) interface{} {
+	ticket := record.(*Ticket)
+	return TicketForDisplay{
+		Ticket:           *ticket,
+		SubmitterName:    firstUser(linked["submitter"]).Name,
+		AssigneeName:     firstUser(linked["assignee"]).Name,
+		OrganizationName: firstOrganization(linked["organization"]).Name,
+	}
+}
+
+//displayUser shows the user with the name of its organization and the IDs of the tickets it submitted and is assigned to
+func displayUser(record interface{}, linked map[string][]interface{}) interface{} {
+	user := record.(*User)
+	return UserForDisplay{
+		User:               *user,
+		OrganizationName:   firstOrganization(linked["organization"]).Name,
+		SubmittedTicketIDs: ticketIDs(linked["submittedTickets"]),
+		AssignedTicketsIDs: ticketIDs(linked["assignedTickets"]),
+	}
+}
+
+//displayOrganization shows the organization with the names of its users and the IDs of its tickets
+func displayOrganization(record interface{}, linked map[string][]interface{}) interface{} {
+	organization := record.(*Organization)
+	userNames := []string{}
+	for _, user := range linked["users"] {
+		userNames = append(userNames, user.(*User).Name)
+	}
+	return OrganizationForDisplay{Organization: *organization, UserNames: userNames, TicketIDs: ticketIDs(linked["tickets"])}
+}
+
+//firstUser returns an empty user when there is no linked user, so a dangling reference shows a blank name
+func firstUser(records []interface{}) *User {
+	if len(records) == 0 {
+		return &User{}
+	}
+	return records[0].(*User)
+}
+
+func firstOrganization(records []interface{}) *Organization {
+	if len(records) == 0 {
+		return &Organization{}
+	}
+	return records[0].(*Organization)
+}
+
+func ticketIDs(records []interface{}) []string {
+	ids := []string{}
+	for _, ticket := range records {
+		ids = append(ids, ticket.(*Ticket).ID)
+	}
+	return ids
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//LoadError describes why a single entity failed to load from its data file.
+//Line and Column are 1-based and Record is the 0-based index of the record in the top level JSON array; they are only set when the position of a JSON error is known.
+type LoadError struct {
+	Entity   string
+	FilePath string
+	Op       string
+	Line     int
+	Column   int
+	Record   int
+	Err      error
+}
+
+func (e *LoadError) Error() string {
+	message := fmt.Sprintf("%s %s file %s failed", e.Op, e.Entity, e.FilePath)
+	if e.Line > 0 {
+		message = fmt.Sprintf("%s at line %d, column %d", message, e.Line, e.Column)
+	}
+	if e.Record >= 0 {
+		message = fmt.Sprintf("%s (record index %d)", message, e.Record)
+	}
+	return fmt.Sprintf("%s: %v", message, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+//LoadErrors collects the LoadError of every entity that failed to load, so a broken export reports all of its problems in one run.
+//It supports errors.Is and errors.As on each of the wrapped errors.
+type LoadErrors []*LoadError
+
+func (e LoadErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, len(e))
+	for i, loadErr := range e {
+		messages[i] = "  - " + loadErr.Error()
+	}
+	return fmt.Sprintf("%d data files failed to load:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+func (e LoadErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, loadErr := range e {
+		errs[i] = loadErr
+	}
+	return errs
+}
+
+func newUnmarshalError(entity, filePath string, content []byte, err error) *LoadError {
+	loadErr := &LoadError{Entity: entity, FilePath: filePath, Op: "unmarshal", Record: -1, Err: err}
+	positionErr, ok := err.(*PositionError)
+	if ok {
+		loadErr.Line, loadErr.Column, loadErr.Record, loadErr.Err = positionErr.Line, positionErr.Column, positionErr.Record, positionErr.Err
+		return loadErr
+	}
+	offset := int64(-1)
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	}
+	if offset >= 0 {
+		loadErr.Line, loadErr.Column, loadErr.Record = locateOffset(bytes.NewReader(content), offset)
+	}
+	return loadErr
+}
+
+//newStreamError tells a failure to open the file apart from a failure to decode it, as a StreamSerializer does both in one call
+func newStreamError(entity, filePath string, err error) *LoadError {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return &LoadError{Entity: entity, FilePath: filePath, Op: "read", Record: -1, Err: err}
+	}
+	return newUnmarshalError(entity, filePath, nil, err)
+}
+
+//PositionError is returned by a StreamSerializer when a record cannot be decoded. Record is the 0-based index of the record in the file,
+//and Line and Column are the 1-based position of the offending byte.
+type PositionError struct {
+	Line   int
+	Column int
+	Record int
+	Err    error
+}
+
+func (e *PositionError) Error() string {
+	return fmt.Sprintf("record index %d at line %d, column %d: %v", e.Record, e.Line, e.Column, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+//locateOffset converts the byte offset reported by encoding/json into the line and column of the offending byte,
+//and counts the elements of the top level array before it to find the index of the broken record (-1 if the top level value is not an array)
+func locateOffset(r io.Reader, offset int64) (line, column, record int) {
+	line, record = 1, -1
+	reader := bufio.NewReader(r)
+	depth := 0
+	inString, escaped := false, false
+	for i := int64(0); i < offset; i++ {
+		c, err := reader.ReadByte()
+		if err != nil {
+			break
+		}
+		if c == '\n' {
+			line++
+			column = 0
+			continue
+		}
+		column++
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\r':
+		case ',':
+			if depth == 1 {
+				record++
+			}
+		case '}', ']':
+			depth--
+		default:
+			if depth == 1 && record < 0 {
+				record = 0
+			}
+			if c == '"' {
+				inString = true
+			}
+			if c == '{' || c == '[' {
+				depth++
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,30 @@
+package data
+
+//UnregisterEntity removes an entity registered by a test, so the other tests keep loading the built-in entities only
+func UnregisterEntity(name string) {
+	entitiesLock.Lock()
+	defer entitiesLock.Unlock()
+	for i, entity := range entities {
+		if entity.Name == name {
+			entities = append(entities[:i], entities[i+1:]...)
+			return
+		}
+	}
+}
+
+//IsMissing returns true when the loader found the field missing from the record, ex. AssigneeID
+func IsMissing(record interface{}, fieldName string) bool {
+	return Field{NameWithCase: fieldName}.IsMissing(record)
+}
+
+//ClearMissing forgets the missing fields of the tickets, so they can be compared with tickets built by a test
+func ClearMissing(tickets []*Ticket) {
+	for _, ticket := range tickets {
+		ticket.missing = nil
+	}
+}
+
+//FuzzyCandidates returns the terms the fuzzy index of the sorted terms compares with the value
+func FuzzyCandidates(terms []string, value string, maxDistance int) []string {
+	return newFuzzyIndex(terms).candidates(value, maxDistance)
+}
@@ -0,0 +1,156 @@
+package data
+
+import (
+	"sort"
+)
+
+//FuzzyDistance is the number of typos tolerated by a fuzzy search and by the suggestions of a search without results, set by the fuzzy-distance setting
+var FuzzyDistance = 2
+
+//Suggestion is an indexed value close to a search value, Distance typos away from it
+type Suggestion struct {
+	Value    string
+	Distance int
+}
+
+//Distance returns the number of typos between a and b: inserted, removed, replaced or swapped adjacent letters, ex. fransisca is 1 typo away from francisca.
+//The count stops at max, so Distance returns max+1 for values which are further apart.
+func Distance(a, b string, max int) int {
+	s, t := []rune(a), []rune(b)
+	if len(s)-len(t) > max || len(t)-len(s) > max {
+		return max + 1
+	}
+	//Three rows of the edit distance matrix are enough, the one before the previous is used by the swapped letters
+	before, previous, current := make([]int, len(t)+1), make([]int, len(t)+1), make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				current[j] = minInt(current[j], before[j-2]+1)
+			}
+			rowMin = minInt(rowMin, current[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		before, previous, current = previous, current, before
+	}
+	return minInt(previous[len(t)], max+1)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+//fuzzyIndex finds the terms of a field close to a search value without comparing the value with every term. It keeps the terms containing each bigram,
+//ie. each pair of adjacent letters with ^ and $ marking the start and the end of the term, and the terms of each length in letters.
+//A typo changes at most 3 bigrams of a value, so a term maxDistance typos away shares all but 3*maxDistance of its distinct bigrams;
+//only the terms sharing enough bigrams, or for a value too short to share any, the terms of a close length, are compared with Distance.
+type fuzzyIndex struct {
+	terms   []string
+	grams   map[string][]int
+	lengths map[int][]int
+}
+
+//newFuzzyIndex indexes the sorted terms by their bigrams and their lengths; the positions in each list are ascending
+func newFuzzyIndex(terms []string) fuzzyIndex {
+	index := fuzzyIndex{terms: terms, grams: map[string][]int{}, lengths: map[int][]int{}}
+	for position, term := range terms {
+		for _, gram := range bigrams(term) {
+			index.grams[gram] = append(index.grams[gram], position)
+		}
+		length := len([]rune(term))
+		index.lengths[length] = append(index.lengths[length], position)
+	}
+	return index
+}
+
+//bigrams returns the distinct bigrams of the value, ex. ^f, fr, ra, an and n$ for fran
+func bigrams(value string) (grams []string) {
+	letters := append(append([]rune{'^'}, []rune(value)...), '$')
+	isAdded := map[string]bool{}
+	for i := 0; i+1 < len(letters); i++ {
+		gram := string(letters[i : i+2])
+		if !isAdded[gram] {
+			isAdded[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return
+}
+
+//candidates returns the terms which may be at most maxDistance typos away from the value, in the order of the terms;
+//every term within the distance is a candidate, but a candidate may still be further away
+func (i fuzzyIndex) candidates(value string, maxDistance int) (candidates []string) {
+	length := len([]rune(value))
+	isCloseLength := func(position int) bool {
+		difference := len([]rune(i.terms[position])) - length
+		return difference <= maxDistance && -difference <= maxDistance
+	}
+	positions := []int{}
+	grams := bigrams(value)
+	minShared := len(grams) - 3*maxDistance
+	if minShared <= 0 {
+		for l := length - maxDistance; l <= length+maxDistance; l++ {
+			positions = append(positions, i.lengths[l]...)
+		}
+	} else {
+		shared := map[int]int{}
+		for _, gram := range grams {
+			for _, position := range i.grams[gram] {
+				shared[position]++
+				if shared[position] == minShared && isCloseLength(position) {
+					positions = append(positions, position)
+				}
+			}
+		}
+	}
+	sort.Ints(positions)
+	for _, position := range positions {
+		candidates = append(candidates, i.terms[position])
+	}
+	return
+}
+
+//closest returns the terms at most maxDistance typos away from the value, the closest first and then in the order of the terms
+func (i fuzzyIndex) closest(value string, maxDistance int) (suggestions []Suggestion) {
+	for _, term := range i.candidates(value, maxDistance) {
+		distance := Distance(value, term, maxDistance)
+		if distance <= maxDistance {
+			suggestions = append(suggestions, Suggestion{Value: term, Distance: distance})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Distance < suggestions[j].Distance
+	})
+	return
+}
+
+//records returns the records of the terms at most maxDistance typos away from the value, in the order of the terms and without duplicates
+func (i fuzzyIndex) records(postings map[string][]interface{}, value string, maxDistance int) (records []interface{}) {
+	isAdded := map[interface{}]bool{}
+	for _, term := range i.candidates(value, maxDistance) {
+		if Distance(value, term, maxDistance) > maxDistance {
+			continue
+		}
+		for _, record := range postings[term] {
+			if !isAdded[record] {
+				isAdded[record] = true
+				records = append(records, record)
+			}
+		}
+	}
+	return
+}
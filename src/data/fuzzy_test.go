@@ -0,0 +1,147 @@
+package data_test
+
+import (
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"strings"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	testCases := map[string]struct {
+		a                string
+		b                string
+		max              int
+		expectedDistance int
+	}{
+		"same value": {
+			a: "francisca", b: "francisca", max: 2,
+			expectedDistance: 0,
+		},
+		"replaced letter": {
+			a: "fransisca", b: "francisca", max: 2,
+			expectedDistance: 1,
+		},
+		"swapped letters are a single typo": {
+			a: "fracnisca", b: "francisca", max: 2,
+			expectedDistance: 1,
+		},
+		"inserted and removed letters": {
+			a: "francsca", b: "franciscaa", max: 2,
+			expectedDistance: 2,
+		},
+		"letters with accents": {
+			a: "rodrigüez", b: "rodriguez", max: 2,
+			expectedDistance: 1,
+		},
+		"count stops after max": {
+			a: "francisca", b: "rasmussen", max: 2,
+			expectedDistance: 3,
+		},
+	}
+	for tc, tp := range testCases {
+		distance := data.Distance(tp.a, tp.b, tp.max)
+		if distance != tp.expectedDistance {
+			t.Errorf("For test case <%s>, Expected distance is <%d>, but actual distance is <%d>", tc, tp.expectedDistance, distance)
+		}
+	}
+}
+
+func TestFieldFuzzy(t *testing.T) {
+	testCases := map[string]struct {
+		field               string
+		value               string
+		maxDistance         int
+		expectedRecords     []interface{}
+		expectedSuggestions []string
+	}{
+		"string field compares the whole value": {
+			field:               "alias",
+			value:               "USR 2",
+			maxDistance:         1,
+			expectedRecords:     []interface{}{mock.MockUsers[1]},
+			expectedSuggestions: []string{"user 2"},
+		},
+		"text field compares every word": {
+			field:               "name",
+			value:               "tset tsetb",
+			maxDistance:         1,
+			expectedRecords:     []interface{}{mock.MockUsers[1]},
+			expectedSuggestions: []string{"test", "testb"},
+		},
+		"too many typos": {
+			field:       "email",
+			value:       "usr1@tst.cm",
+			maxDistance: 2,
+		},
+	}
+	for tc, tp := range testCases {
+		field := mock.MockStructMap["users"][tp.field]
+		records, err := field.Fuzzy(tp.value, tp.maxDistance)
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		assertRecords(t, tc, tp.expectedRecords, records)
+		suggestions := []string{}
+		for _, suggestion := range field.Suggest(tp.value, tp.maxDistance) {
+			suggestions = append(suggestions, suggestion.Value)
+		}
+		if len(suggestions) != len(tp.expectedSuggestions) {
+			t.Errorf("For test case <%s>, Expected suggestions are <%v>, but actual suggestions are <%v>", tc, tp.expectedSuggestions, suggestions)
+			continue
+		}
+		for i := range suggestions {
+			if suggestions[i] != tp.expectedSuggestions[i] {
+				t.Errorf("For test case <%s>, Expected suggestions are <%v>, but actual suggestions are <%v>", tc, tp.expectedSuggestions, suggestions)
+				break
+			}
+		}
+	}
+}
+
+func TestFuzzyCandidates(t *testing.T) {
+	terms := []string{"ab", "ba", "cross", "fancisca", "francesca", "francis", "francisca", "franciscaa", "fransisca", "rasmussen", "rodriguez", "rodrígues", "x"}
+	testCases := map[string]struct {
+		value              string
+		maxDistance        int
+		expectedCandidates []string
+	}{
+		"long value only compares the terms sharing its bigrams": {
+			value:              "fransisca",
+			maxDistance:        1,
+			expectedCandidates: []string{"francesca", "francisca", "franciscaa", "fransisca"},
+		},
+		"swapped letters share enough bigrams": {
+			value:              "fracnisca",
+			maxDistance:        1,
+			expectedCandidates: []string{"francisca", "franciscaa", "fransisca"},
+		},
+		"short value compares the terms of a close length": {
+			value:              "ab",
+			maxDistance:        1,
+			expectedCandidates: []string{"ab", "ba", "x"},
+		},
+	}
+	for tc, tp := range testCases {
+		candidates := data.FuzzyCandidates(terms, tp.value, tp.maxDistance)
+		if strings.Join(candidates, ",") != strings.Join(tp.expectedCandidates, ",") {
+			t.Errorf("For test case <%s>, Expected candidates are <%v>, but actual candidates are <%v>", tc, tp.expectedCandidates, candidates)
+		}
+	}
+
+	//Every term within the distance must be a candidate
+	for _, value := range append(terms, "fracnsica", "rodrigez", "rasmusen", "crss", "") {
+		for maxDistance := 0; maxDistance <= 3; maxDistance++ {
+			isCandidate := map[string]bool{}
+			for _, candidate := range data.FuzzyCandidates(terms, value, maxDistance) {
+				isCandidate[candidate] = true
+			}
+			for _, term := range terms {
+				if data.Distance(value, term, maxDistance) <= maxDistance && !isCandidate[term] {
+					t.Errorf("Expected %s is a candidate for %s with %d typos, but actually not", term, value, maxDistance)
+				}
+			}
+		}
+	}
+}
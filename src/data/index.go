@@ -0,0 +1,318 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//FieldIndex looks up the records of a field by a search value. The value is parsed as the field type first,
+//so an int field is compared as a number and searching it with "abc" is rejected instead of returning no results.
+type FieldIndex interface {
+	//Lookup returns the records whose value equals the search value, or an error when the value is not valid for the field type
+	Lookup(value string) (records []interface{}, err error)
+}
+
+//RangeIndex is a FieldIndex kept sorted by value, implemented by the int, float and time fields
+type RangeIndex interface {
+	FieldIndex
+	//Range returns the records whose value is between the bounds, in ascending order of the value
+	Range(lower, upper Bound) (records []interface{}, err error)
+}
+
+//PatternIndex is a FieldIndex whose values can be matched by a wildcard or a regular expression, implemented by the string, list and text fields.
+//It keeps the values sorted, so the values of a prefix are found without scanning the others.
+type PatternIndex interface {
+	FieldIndex
+	//Match returns the records with a value matching the pattern; the words of a text field are matched one by one
+	Match(pattern Pattern) (records []interface{}, err error)
+}
+
+//FuzzyIndex is a FieldIndex which tolerates typos in the search value, implemented by the string, list and text fields
+type FuzzyIndex interface {
+	FieldIndex
+	//Fuzzy returns the records with a value at most maxDistance typos away from the search value; the words of a text field are compared one by one
+	Fuzzy(value string, maxDistance int) (records []interface{}, err error)
+	//Suggest returns the indexed values at most maxDistance typos away from the search value, the closest first
+	Suggest(value string, maxDistance int) []Suggestion
+}
+
+//ScoredIndex is a FieldIndex which ranks its matches, implemented by the full-text fields
+type ScoredIndex interface {
+	FieldIndex
+	//Score returns the relevance of a record for the search value, 0 when the record does not match
+	Score(value string, record interface{}) float64
+}
+
+//Bound is a limit of a range search; a Bound with an empty Value leaves the range open on that side
+type Bound struct {
+	Value     string
+	Inclusive bool
+}
+
+//TypeError is returned when a search value cannot be parsed as the type of the searched field
+type TypeError struct {
+	Value string
+	Type  string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%q is not a valid %s", e.Value, e.Type)
+}
+
+//BuildIndexes sets the index of every field in the struct map; it is run once the struct map is built or read from a snapshot
+func BuildIndexes(structMap map[string]map[string]Field) {
+	for _, fieldMap := range structMap {
+		for key, field := range fieldMap {
+			field.Index = NewFieldIndex(field)
+			fieldMap[key] = field
+		}
+	}
+}
+
+//NewFieldIndex builds the index of the field from its value map: int and float64 fields get a sorted numeric index, time fields a sorted time index,
+//bool fields a boolean index, text fields a full-text index and the other fields (string, []string) look up the lower case value
+func NewFieldIndex(field Field) FieldIndex {
+	switch field.Type {
+	case "int", "float64":
+		return newSortedIndex(field, func(value string) (sortKey, error) {
+			return parseNumber(field.Type, value)
+		})
+	case "time":
+		return newSortedIndex(field, func(value string) (sortKey, error) {
+			//now is only meant for search values, ex. due_at < now
+			if strings.EqualFold(strings.TrimSpace(value), "now") {
+				return timeKey(time.Now()), nil
+			}
+			t, err := ParseTimestamp(value)
+			if err != nil {
+				return nil, &TypeError{Value: value, Type: "timestamp"}
+			}
+			return timeKey(t), nil
+		})
+	case "bool":
+		return boolIndex{valueMap: field.ValueMap}
+	case "text":
+		return newTextIndex(field)
+	}
+	terms := sortedKeys(field.ValueMap)
+	return stringIndex{valueMap: field.ValueMap, terms: terms, fuzzy: newFuzzyIndex(terms)}
+}
+
+//Lookup searches the field with its index
+func (f Field) Lookup(value string) ([]interface{}, error) {
+	return f.index().Lookup(value)
+}
+
+//IsPattern returns true when the field can be searched by a pattern, ie. string, list and text fields
+func (f Field) IsPattern() bool {
+	_, ok := f.index().(PatternIndex)
+	return ok
+}
+
+//Match searches the records with a value matching the pattern
+func (f Field) Match(pattern Pattern) ([]interface{}, error) {
+	index, ok := f.index().(PatternIndex)
+	if !ok {
+		return nil, fmt.Errorf("pattern search is not supported on %s fields", f.Type)
+	}
+	return index.Match(pattern)
+}
+
+//IsFuzzy returns true when the field tolerates typos in the search value, ie. string, list and text fields
+func (f Field) IsFuzzy() bool {
+	_, ok := f.index().(FuzzyIndex)
+	return ok
+}
+
+//Fuzzy searches the records with a value at most maxDistance typos away from the search value
+func (f Field) Fuzzy(value string, maxDistance int) ([]interface{}, error) {
+	index, ok := f.index().(FuzzyIndex)
+	if !ok {
+		return nil, fmt.Errorf("fuzzy search is not supported on %s fields", f.Type)
+	}
+	return index.Fuzzy(value, maxDistance)
+}
+
+//Suggest returns the values of the field close to the search value; the fields which do not tolerate typos have no suggestion
+func (f Field) Suggest(value string, maxDistance int) []Suggestion {
+	index, ok := f.index().(FuzzyIndex)
+	if !ok {
+		return nil
+	}
+	return index.Suggest(value, maxDistance)
+}
+
+//Score returns the relevance of the record for the search value weighted by the field boost; only the full-text fields score their matches, the other fields return 0
+func (f Field) Score(value string, record interface{}) float64 {
+	index, ok := f.index().(ScoredIndex)
+	if !ok {
+		return 0
+	}
+	boost := f.Boost
+	if boost == 0 {
+		boost = 1
+	}
+	return boost * index.Score(value, record)
+}
+
+//IsRange returns true when the field can be searched by a range, ie. int, float and time fields
+func (f Field) IsRange() bool {
+	_, ok := f.index().(RangeIndex)
+	return ok
+}
+
+//Range searches the records between the bounds with the sorted index of the field
+func (f Field) Range(lower, upper Bound) ([]interface{}, error) {
+	index, ok := f.index().(RangeIndex)
+	if !ok {
+		return nil, fmt.Errorf("range search is not supported on %s fields", f.Type)
+	}
+	return index.Range(lower, upper)
+}
+
+//index returns the index of the field, building it when the field was created without one
+func (f Field) index() FieldIndex {
+	if f.Index == nil {
+		return NewFieldIndex(f)
+	}
+	return f.Index
+}
+
+type stringIndex struct {
+	valueMap map[string][]interface{}
+	terms    []string
+	fuzzy    fuzzyIndex
+}
+
+func (i stringIndex) Lookup(value string) ([]interface{}, error) {
+	return i.valueMap[strings.ToLower(value)], nil
+}
+
+func (i stringIndex) Match(pattern Pattern) ([]interface{}, error) {
+	return matchTerms(i.terms, i.valueMap, pattern)
+}
+
+func (i stringIndex) Fuzzy(value string, maxDistance int) ([]interface{}, error) {
+	return i.fuzzy.records(i.valueMap, strings.ToLower(strings.TrimSpace(value)), maxDistance), nil
+}
+
+func (i stringIndex) Suggest(value string, maxDistance int) []Suggestion {
+	return i.fuzzy.closest(strings.ToLower(strings.TrimSpace(value)), maxDistance)
+}
+
+type boolIndex struct {
+	valueMap map[string][]interface{}
+}
+
+//Lookup only accepts true and false, as strconv.ParseBool would also read "1" and "t" as true
+func (i boolIndex) Lookup(value string) ([]interface{}, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if normalized != "true" && normalized != "false" {
+		return nil, &TypeError{Value: value, Type: "bool"}
+	}
+	return i.valueMap[normalized], nil
+}
+
+//sortKey is a parsed value of a sorted index
+type sortKey interface {
+	less(other sortKey) bool
+}
+
+type numberKey float64
+
+func (k numberKey) less(other sortKey) bool { return k < other.(numberKey) }
+
+type timeKey time.Time
+
+func (k timeKey) less(other sortKey) bool { return time.Time(k).Before(time.Time(other.(timeKey))) }
+
+func parseNumber(fieldType, value string) (sortKey, error) {
+	trimmed := strings.TrimSpace(value)
+	if fieldType == "int" {
+		i, err := strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return nil, &TypeError{Value: value, Type: "int"}
+		}
+		return numberKey(i), nil
+	}
+	f, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		return nil, &TypeError{Value: value, Type: "float"}
+	}
+	return numberKey(f), nil
+}
+
+type sortedEntry struct {
+	key     sortKey
+	value   string
+	records []interface{}
+}
+
+//sortedIndex keeps the parsed values in ascending order. Values which cannot be parsed, such as a missing timestamp, are only found by an empty search value.
+type sortedIndex struct {
+	entries  []sortedEntry
+	parse    func(value string) (sortKey, error)
+	valueMap map[string][]interface{}
+}
+
+func newSortedIndex(field Field, parse func(value string) (sortKey, error)) *sortedIndex {
+	index := &sortedIndex{parse: parse, valueMap: field.ValueMap}
+	for value, records := range field.ValueMap {
+		key, err := parse(value)
+		if err != nil {
+			continue
+		}
+		index.entries = append(index.entries, sortedEntry{key: key, value: value, records: records})
+	}
+	//Equal keys, such as the same time in two time zones, are ordered by their text so the results do not depend on the map order
+	sort.Slice(index.entries, func(i, j int) bool {
+		a, b := index.entries[i], index.entries[j]
+		if a.key.less(b.key) || b.key.less(a.key) {
+			return a.key.less(b.key)
+		}
+		return a.value < b.value
+	})
+	return index
+}
+
+func (i *sortedIndex) Lookup(value string) ([]interface{}, error) {
+	if len(strings.TrimSpace(value)) == 0 {
+		return i.valueMap[""], nil
+	}
+	return i.Range(Bound{Value: value, Inclusive: true}, Bound{Value: value, Inclusive: true})
+}
+
+func (i *sortedIndex) Range(lower, upper Bound) (records []interface{}, err error) {
+	start, end := 0, len(i.entries)
+	if len(lower.Value) != 0 {
+		key, err := i.parse(lower.Value)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(i.entries), func(n int) bool {
+			if lower.Inclusive {
+				return !i.entries[n].key.less(key)
+			}
+			return key.less(i.entries[n].key)
+		})
+	}
+	if len(upper.Value) != 0 {
+		key, err := i.parse(upper.Value)
+		if err != nil {
+			return nil, err
+		}
+		end = sort.Search(len(i.entries), func(n int) bool {
+			if upper.Inclusive {
+				return key.less(i.entries[n].key)
+			}
+			return !i.entries[n].key.less(key)
+		})
+	}
+	for n := start; n < end; n++ {
+		records = append(records, i.entries[n].records...)
+	}
+	return
+}
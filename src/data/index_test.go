@@ -0,0 +1,160 @@
+package data_test
+
+import (
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"testing"
+)
+
+func TestFieldIndexLookup(t *testing.T) {
+	testCases := map[string]struct {
+		entity               string
+		field                string
+		value                string
+		expectedRecords      []interface{}
+		expectedErrorMessage string
+	}{
+		"int field matches the number": {
+			entity:          "users",
+			field:           "id",
+			value:           " 02",
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+		"int field rejects a value which is not a number": {
+			entity:               "users",
+			field:                "id",
+			value:                "abc",
+			expectedErrorMessage: `"abc" is not a valid int`,
+		},
+		"int field rejects a decimal": {
+			entity:               "users",
+			field:                "organizationid",
+			value:                "1.5",
+			expectedErrorMessage: `"1.5" is not a valid int`,
+		},
+		"bool field matches case insensitive": {
+			entity:          "users",
+			field:           "suspended",
+			value:           "FALSE",
+			expectedRecords: []interface{}{mock.MockUsers[0], mock.MockUsers[1]},
+		},
+		"bool field rejects 1": {
+			entity:               "users",
+			field:                "active",
+			value:                "1",
+			expectedErrorMessage: `"1" is not a valid bool`,
+		},
+		"time field matches the same time in another time zone": {
+			entity:          "users",
+			field:           "createdat",
+			value:           "2019-04-11T21:00:02 +10:00",
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+		"time field rejects a value which is not a timestamp": {
+			entity:               "users",
+			field:                "lastloginat",
+			value:                "yesterday",
+			expectedErrorMessage: `"yesterday" is not a valid timestamp`,
+		},
+		"text field matches a word of the value": {
+			entity:          "tickets",
+			field:           "description",
+			value:           "DESCRIPTION",
+			expectedRecords: []interface{}{mock.MockTickets[0]},
+		},
+		"text field matches every word in any order, ignoring stop words": {
+			entity:          "users",
+			field:           "signature",
+			value:           "the signature2 of user",
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+		"text field does not match a part of a word": {
+			entity:          "tickets",
+			field:           "description",
+			value:           "desc",
+			expectedRecords: []interface{}{},
+		},
+		"text field matches an empty value": {
+			entity:          "users",
+			field:           "signature",
+			value:           "",
+			expectedRecords: []interface{}{mock.MockUsers[0]},
+		},
+		"string field matches the lower case value": {
+			entity:          "users",
+			field:           "role",
+			value:           "Admin",
+			expectedRecords: []interface{}{mock.MockUsers[0]},
+		},
+	}
+	for tc, tp := range testCases {
+		field := mock.MockStructMap[tp.entity][tp.field]
+		records, err := data.NewFieldIndex(field).Lookup(tp.value)
+		if len(tp.expectedErrorMessage) != 0 {
+			if err == nil || err.Error() != tp.expectedErrorMessage {
+				t.Errorf("For test case <%s>, Expected error message is <%s>, but actual error is <%v>", tc, tp.expectedErrorMessage, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		assertRecords(t, tc, tp.expectedRecords, records)
+	}
+}
+
+func TestRangeIndex(t *testing.T) {
+	testCases := map[string]struct {
+		field           string
+		lower           data.Bound
+		upper           data.Bound
+		expectedRecords []interface{}
+	}{
+		"inclusive bounds": {
+			field:           "id",
+			lower:           data.Bound{Value: "1", Inclusive: true},
+			upper:           data.Bound{Value: "2", Inclusive: true},
+			expectedRecords: []interface{}{mock.MockUsers[0], mock.MockUsers[1]},
+		},
+		"exclusive lower bound": {
+			field:           "id",
+			lower:           data.Bound{Value: "1"},
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+		"exclusive upper bound on time": {
+			field:           "lastloginat",
+			upper:           data.Bound{Value: "2019-05-12T11:00:02"},
+			expectedRecords: []interface{}{mock.MockUsers[0]},
+		},
+		"empty range": {
+			field: "id",
+			lower: data.Bound{Value: "3", Inclusive: true},
+		},
+	}
+	for tc, tp := range testCases {
+		index, ok := data.NewFieldIndex(mock.MockStructMap["users"][tp.field]).(data.RangeIndex)
+		if !ok {
+			t.Errorf("For test case <%s>, Expected the %s field has a range index, but actually not", tc, tp.field)
+			continue
+		}
+		records, err := index.Range(tp.lower, tp.upper)
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		assertRecords(t, tc, tp.expectedRecords, records)
+	}
+}
+
+func assertRecords(t *testing.T, tc string, expectedRecords, records []interface{}) {
+	if len(records) != len(expectedRecords) {
+		t.Errorf("For test case <%s>, Expected <%d> records, but actual records are <%v>", tc, len(expectedRecords), records)
+		return
+	}
+	for i := range records {
+		if records[i] != expectedRecords[i] {
+			t.Errorf("For test case <%s>, Expected record <%d> is <%v>, but actually is <%v>", tc, i, expectedRecords[i], records[i])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+//MaxPatternTerms limits the values of a field a pattern may test, so a pattern without a selective prefix, ex. /.*a.*/ on a large field,
+//is rejected instead of testing every value
+var MaxPatternTerms = 10000
+
+//Pattern matches the values of a field by a wildcard, ex. fran* or *@flotonic.com, or by a regular expression, ex. /^fran.*a$/.
+//Prefix is the lower case text every matched value starts with; the matched values are found by a binary search of the prefix in the sorted terms.
+type Pattern struct {
+	Text   string
+	Prefix string
+	expr   *regexp.Regexp
+}
+
+//WildcardPattern matches the whole value, where * stands for any text, ex. fran* matches francisca and *@flotonic.com the emails of flotonic.com
+func WildcardPattern(wildcard string) (pattern Pattern, err error) {
+	parts := strings.Split(strings.ToLower(wildcard), "*")
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = regexp.QuoteMeta(part)
+	}
+	expr, err := regexp.Compile("^" + strings.Join(quoted, ".*") + "$")
+	if err != nil {
+		return
+	}
+	return Pattern{Text: wildcard, Prefix: parts[0], expr: expr}, nil
+}
+
+//RegexpPattern matches the values containing a match of the case insensitive regular expression; anchor it with ^ and $ to match the whole value
+func RegexpPattern(expr string) (pattern Pattern, err error) {
+	compiled, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return pattern, fmt.Errorf("invalid regular expression /%s/: %v", expr, err)
+	}
+	return Pattern{Text: "/" + expr + "/", Prefix: literalPrefix(expr), expr: compiled}, nil
+}
+
+//literalPrefix returns the literal text an expression anchored by ^ starts with, ex. fran for ^fran.*a$; an expression which is not anchored may match anywhere in the value, so it has no prefix
+func literalPrefix(expr string) string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 || re.Sub[0].Op != syntax.OpBeginText || re.Sub[1].Op != syntax.OpLiteral {
+		return ""
+	}
+	return strings.ToLower(string(re.Sub[1].Rune))
+}
+
+//matchTerms returns the records of the sorted terms matching the pattern, in the order of the terms and without duplicates.
+//Only the terms starting with the pattern prefix are tested, and no more than MaxPatternTerms of them.
+func matchTerms(terms []string, postings map[string][]interface{}, pattern Pattern) (records []interface{}, err error) {
+	isAdded := map[interface{}]bool{}
+	tested := 0
+	for _, term := range terms[sort.SearchStrings(terms, pattern.Prefix):] {
+		if !strings.HasPrefix(term, pattern.Prefix) {
+			break
+		}
+		tested++
+		if tested > MaxPatternTerms {
+			return nil, fmt.Errorf("the pattern %s tests more than %d values of the field, start it with a longer text", pattern.Text, MaxPatternTerms)
+		}
+		if !pattern.expr.MatchString(term) {
+			continue
+		}
+		for _, record := range postings[term] {
+			if !isAdded[record] {
+				isAdded[record] = true
+				records = append(records, record)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,94 @@
+package data_test
+
+import (
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"testing"
+)
+
+func TestFieldMatch(t *testing.T) {
+	testCases := map[string]struct {
+		field                string
+		pattern              string
+		isRegexp             bool
+		maxPatternTerms      int
+		expectedRecords      []interface{}
+		expectedErrorMessage string
+	}{
+		"wildcard prefix": {
+			field:           "url",
+			pattern:         "HTTP://U1*",
+			expectedRecords: []interface{}{mock.MockUsers[0]},
+		},
+		"wildcard suffix": {
+			field:           "email",
+			pattern:         "*@test.com",
+			expectedRecords: []interface{}{mock.MockUsers[0], mock.MockUsers[1]},
+		},
+		"wildcard matches the words of a text field": {
+			field:           "name",
+			pattern:         "testb*",
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+		"wildcard without a star matches the whole value only": {
+			field:   "email",
+			pattern: "user1",
+		},
+		"regular expression matches anywhere in the value": {
+			field:           "externalid",
+			pattern:         "2",
+			isRegexp:        true,
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+		"anchored regular expression": {
+			field:           "alias",
+			pattern:         "^USER [0-9]$",
+			isRegexp:        true,
+			expectedRecords: []interface{}{mock.MockUsers[0], mock.MockUsers[1]},
+		},
+		"regular expression testing too many values": {
+			field:                "externalid",
+			pattern:              "u",
+			isRegexp:             true,
+			maxPatternTerms:      1,
+			expectedErrorMessage: "the pattern /u/ tests more than 1 values of the field, start it with a longer text",
+		},
+		"prefix of an anchored regular expression narrows the tested values": {
+			field:           "externalid",
+			pattern:         "^u2",
+			isRegexp:        true,
+			maxPatternTerms: 1,
+			expectedRecords: []interface{}{mock.MockUsers[1]},
+		},
+	}
+	defer func(max int) { data.MaxPatternTerms = max }(data.MaxPatternTerms)
+	for tc, tp := range testCases {
+		data.MaxPatternTerms = 10000
+		if tp.maxPatternTerms != 0 {
+			data.MaxPatternTerms = tp.maxPatternTerms
+		}
+		var pattern data.Pattern
+		var err error
+		if tp.isRegexp {
+			pattern, err = data.RegexpPattern(tp.pattern)
+		} else {
+			pattern, err = data.WildcardPattern(tp.pattern)
+		}
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected the pattern is valid, but actual error is <%v>", tc, err)
+			continue
+		}
+		records, err := mock.MockStructMap["users"][tp.field].Match(pattern)
+		if len(tp.expectedErrorMessage) != 0 {
+			if err == nil || err.Error() != tp.expectedErrorMessage {
+				t.Errorf("For test case <%s>, Expected error message is <%s>, but actual error is <%v>", tc, tp.expectedErrorMessage, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		assertRecords(t, tc, tp.expectedRecords, records)
+	}
+}
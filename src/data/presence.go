@@ -0,0 +1,130 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+//EmptyKeyword searched as the value of a field finds the records without a value for it, ex. assignee_id:empty
+const EmptyKeyword = "empty"
+
+//fieldSet holds the names of the fields which are missing from a record in its data file, ex. AssigneeID
+type fieldSet map[string]bool
+
+//presenceRecord is implemented by the records which remember the fields missing from their data file or set to null,
+//so an absent int is told apart from a real 0
+type presenceRecord interface {
+	missingFields() fieldSet
+	setMissingFields(missing fieldSet)
+}
+
+func (t *Ticket) missingFields() fieldSet {
+	return t.missing
+}
+
+func (u *User) missingFields() fieldSet {
+	return u.missing
+}
+
+func (o *Organization) missingFields() fieldSet {
+	return o.missing
+}
+
+func (r *Record) missingFields() fieldSet {
+	return r.missing
+}
+
+func (t *Ticket) setMissingFields(missing fieldSet) {
+	t.missing = missing
+}
+
+func (u *User) setMissingFields(missing fieldSet) {
+	u.missing = missing
+}
+
+func (o *Organization) setMissingFields(missing fieldSet) {
+	o.missing = missing
+}
+
+func (r *Record) setMissingFields(missing fieldSet) {
+	r.missing = missing
+}
+
+//trackPresence remembers the fields of the record, a pointer to a built-in record struct, whose json name is not a key of its raw JSON object or holds null.
+//A schema Record finds its missing fields when it is bound to its entity instead.
+func trackPresence(raw []byte, record interface{}) {
+	r, ok := record.(presenceRecord)
+	if !ok {
+		return
+	}
+	if _, isSchemaRecord := record.(*Record); isSchemaRecord {
+		return
+	}
+	keys := map[string]json.RawMessage{}
+	err := json.Unmarshal(raw, &keys)
+	if err != nil {
+		return
+	}
+	//encoding/json matches the keys case insensitively, so the presence is checked the same way
+	present := fieldSet{}
+	for key, value := range keys {
+		if string(value) != "null" {
+			present[strings.ToLower(key)] = true
+		}
+	}
+	missing := fieldSet{}
+	recordType := reflect.TypeOf(record).Elem()
+	for i := 0; i < recordType.NumField(); i++ {
+		name := strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0]
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		if !present[strings.ToLower(name)] {
+			missing[recordType.Field(i).Name] = true
+		}
+	}
+	r.setMissingFields(missing)
+}
+
+//IsMissing returns true when the record has no value for this field in its data file, or null
+func (f Field) IsMissing(record interface{}) bool {
+	r, ok := record.(presenceRecord)
+	return ok && r.missingFields()[f.NameWithCase]
+}
+
+//markMissing adds the field to the missing fields of the record; an index snapshot restores them this way, as gob does not encode the unexported fields of the records
+func markMissing(record interface{}, fieldName string) {
+	r, ok := record.(presenceRecord)
+	if !ok {
+		return
+	}
+	missing := r.missingFields()
+	if missing == nil {
+		missing = fieldSet{}
+		r.setMissingFields(missing)
+	}
+	missing[fieldName] = true
+}
+
+//isBlank returns true for an empty string or an empty list
+func isBlank(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
+
+//Empty returns the records without a value for this field: the blank ones first, then the missing ones
+func (f Field) Empty() []interface{} {
+	records := append([]interface{}{}, f.Blank...)
+	return append(records, f.Missing...)
+}
+
+//IsEmptyKeyword returns true when the search value asks for the records without a value, ie. empty in any case
+func IsEmptyKeyword(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), EmptyKeyword)
+}
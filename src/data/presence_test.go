@@ -0,0 +1,67 @@
+package data_test
+
+import (
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestProcessFieldMapPresence(t *testing.T) {
+	content := `{"_id": "t1", "assignee_id": 0, "description": "", "tags": []}
+{"_id": "t2", "assignee_id": null, "tags": ["ohio"]}
+{"_id": "t3", "assignee_id": 38, "description": "a drama", "tags": ["utah"]}`
+	tickets := []*data.Ticket{}
+	err := data.NewStreamSerializer().Unmarshal([]byte(content), &tickets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []interface{}{}
+	for _, ticket := range tickets {
+		records = append(records, ticket)
+	}
+	fieldMap := data.ProcessFieldMap(records)
+
+	testCases := map[string]struct {
+		fieldKey        string
+		expectedMissing []interface{}
+		expectedEmpty   []interface{}
+	}{
+		"a null int is missing, while a real 0 is not": {
+			fieldKey:        "assigneeid",
+			expectedMissing: []interface{}{tickets[1]},
+			expectedEmpty:   []interface{}{tickets[1]},
+		},
+		"an empty string is blank and an absent string is missing": {
+			fieldKey:        "description",
+			expectedMissing: []interface{}{tickets[1]},
+			expectedEmpty:   []interface{}{tickets[0], tickets[1]},
+		},
+		"an empty list is blank": {
+			fieldKey:      "tags",
+			expectedEmpty: []interface{}{tickets[0]},
+		},
+	}
+	for tc, tp := range testCases {
+		field := fieldMap[tp.fieldKey]
+		if !sameRecords(field.Missing, tp.expectedMissing) {
+			t.Errorf("For test case <%s>, Expected missing records are <%v>, but actual records are <%v>", tc, tp.expectedMissing, field.Missing)
+		}
+		if !sameRecords(field.Empty(), tp.expectedEmpty) {
+			t.Errorf("For test case <%s>, Expected empty records are <%v>, but actual records are <%v>", tc, tp.expectedEmpty, field.Empty())
+		}
+	}
+	if len(fieldMap["assigneeid"].ValueMap["0"]) != 1 {
+		t.Errorf("Expected only the ticket with a real 0 is indexed under 0, but actual records are <%v>", fieldMap["assigneeid"].ValueMap["0"])
+	}
+}
+
+func sameRecords(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
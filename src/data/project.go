@@ -0,0 +1,180 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+)
+
+//Projection is a displayed record projected down to the selected attributes, written as a JSON object with the attributes in the selected order
+type Projection struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (p Projection) MarshalJSON() ([]byte, error) {
+	return marshalOrdered(p.keys, p.values)
+}
+
+//Project returns the attributes of the display of the record at the paths, keyed by the path as it was selected, ex. subject, assignee_name or organization.tags.
+//A path names an attribute of the display, or follows the relations of the entity to an attribute of the displayed linked records, ex. submitter.organization.name;
+//a relation to several records, ex. submitted_tickets.subject, gives the list of their values. A path which is not an attribute of the entity is left out.
+func (e Entity) Project(record, display interface{}, paths []string, structMap map[string]map[string]Field) Projection {
+	projection := Projection{values: map[string]interface{}{}}
+	for _, path := range paths {
+		value, ok := e.attribute(record, display, strings.Split(path, "."), structMap)
+		if !ok {
+			continue
+		}
+		if _, isSelected := projection.values[path]; !isSelected {
+			projection.keys = append(projection.keys, path)
+		}
+		projection.values[path] = value
+	}
+	return projection
+}
+
+//HasAttribute returns true when the path is an attribute of the displayed records of the entity, or of the entities its relations lead to, ex. assignee.email
+func (e Entity) HasAttribute(path string) bool {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		relation, ok := e.Relation(name)
+		if !ok {
+			return false
+		}
+		e, ok = LookupEntity(relation.Target)
+		if !ok {
+			return false
+		}
+	}
+	_, ok := displayAttribute(e.show(e.NewRecord(), map[string][]interface{}{}), names[len(names)-1])
+	return ok
+}
+
+//Attributes returns the names of the attributes of the displayed records of the entity, in the order they are displayed, ex. _id, url, ..., submitter_name
+func (e Entity) Attributes() (names []string) {
+	display := e.show(e.NewRecord(), map[string][]interface{}{})
+	if _, ok := display.(RecordForDisplay); ok {
+		for _, field := range e.Fields {
+			names = append(names, field.Name)
+		}
+		for _, relation := range e.Relations {
+			names = append(names, relation.Name)
+		}
+		return
+	}
+	return structAttributes(reflect.TypeOf(display))
+}
+
+//attribute returns the value at the path of the displayed record; the first names of a longer path are relations followed to the linked records
+func (e Entity) attribute(record, display interface{}, path []string, structMap map[string]map[string]Field) (interface{}, bool) {
+	if len(path) == 1 {
+		return displayAttribute(display, path[0])
+	}
+	relation, ok := e.Relation(path[0])
+	if !ok {
+		return nil, false
+	}
+	target, ok := LookupEntity(relation.Target)
+	if !ok || !target.HasAttribute(strings.Join(path[1:], ".")) {
+		return nil, false
+	}
+	values := []interface{}{}
+	for _, linked := range e.Linked(record, structMap)[relation.Name] {
+		value, _ := target.attribute(linked, target.show(linked, target.Linked(linked, structMap)), path[1:], structMap)
+		values = append(values, value)
+	}
+	//A relation to the ID of its target links one record at most, so it gives a single value, or null for a dangling reference
+	if relation.TargetField == target.IDField {
+		if len(values) == 0 {
+			return nil, true
+		}
+		return values[0], true
+	}
+	return values, true
+}
+
+//show returns the display of the record, or the record itself when the entity has no Display
+func (e Entity) show(record interface{}, linked map[string][]interface{}) interface{} {
+	if e.Display == nil {
+		return record
+	}
+	return e.Display(record, linked)
+}
+
+//displayAttribute returns the attribute of a display whose JSON name is the name compared like a field key, ex. assignee_name or _id;
+//the attributes of an embedded record struct, ex. the Ticket of a TicketForDisplay, are attributes of the display
+func displayAttribute(display interface{}, name string) (interface{}, bool) {
+	if r, ok := display.(RecordForDisplay); ok {
+		entity, _ := LookupEntity(r.Record.Entity)
+		for _, field := range entity.Fields {
+			if FieldKey(field.Name) == FieldKey(name) {
+				return r.Record.Values[field.Name], true
+			}
+		}
+		for _, relation := range entity.Relations {
+			if FieldKey(relation.Name) == FieldKey(name) {
+				return r.Linked[relation.Name], true
+			}
+		}
+		return nil, false
+	}
+	v := reflect.ValueOf(display)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous {
+			value, ok := displayAttribute(v.Field(i).Interface(), name)
+			if ok {
+				return value, true
+			}
+			continue
+		}
+		jsonName := attributeName(field)
+		if len(jsonName) != 0 && FieldKey(jsonName) == FieldKey(name) {
+			return v.Field(i).Interface(), true
+		}
+	}
+	return nil, false
+}
+
+//structAttributes returns the JSON names of the attributes of a display struct type, with the attributes of the embedded structs in their place
+func structAttributes(t reflect.Type) (names []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			names = append(names, structAttributes(field.Type)...)
+			continue
+		}
+		jsonName := attributeName(field)
+		if len(jsonName) != 0 {
+			names = append(names, jsonName)
+		}
+	}
+	return
+}
+
+//attributeName returns the JSON name of an exported struct field, or an empty name for the unexported fields and the fields left out of the JSON
+func attributeName(field reflect.StructField) string {
+	if len(field.PkgPath) != 0 {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if len(name) == 0 {
+		return field.Name
+	}
+	return name
+}
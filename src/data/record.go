@@ -0,0 +1,202 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+//Record is a record of a schema entity. Values holds the typed value of every defined field, keyed by the field name in the data file;
+//a field missing from the data file holds the zero value of its type, as the fields of the built-in records do, and is named in missing.
+type Record struct {
+	Entity  string
+	Values  map[string]interface{}
+	raw     map[string]json.RawMessage
+	missing fieldSet
+}
+
+//UnmarshalJSON decodes the record against the fields of its entity. A record created without its entity, ex. by json.Unmarshal into []*Record,
+//keeps the raw values until the loader binds it to the entity.
+func (r *Record) UnmarshalJSON(b []byte) error {
+	raw := map[string]json.RawMessage{}
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	r.raw = raw
+	if len(r.Entity) == 0 {
+		return nil
+	}
+	return r.bind(r.Entity)
+}
+
+//MarshalJSON writes the fields in the order of the schema
+func (r *Record) MarshalJSON() ([]byte, error) {
+	entity, ok := LookupEntity(r.Entity)
+	if !ok {
+		return json.Marshal(r.Values)
+	}
+	keys := make([]string, len(entity.Fields))
+	for i, field := range entity.Fields {
+		keys[i] = field.Name
+	}
+	return marshalOrdered(keys, r.Values)
+}
+
+func (r *Record) bind(entityName string) error {
+	entity, ok := LookupEntity(entityName)
+	if !ok {
+		return fmt.Errorf("entity %s is not registered", entityName)
+	}
+	values := map[string]interface{}{}
+	missing := fieldSet{}
+	for _, field := range entity.Fields {
+		raw := r.raw[field.Name]
+		if len(raw) == 0 || string(raw) == "null" {
+			missing[field.Name] = true
+		}
+		value, err := decodeFieldValue(field.Type, raw)
+		if err != nil {
+			return fmt.Errorf("field %q: %v", field.Name, err)
+		}
+		values[field.Name] = value
+	}
+	r.Entity, r.Values, r.raw, r.missing = entityName, values, nil, missing
+	return nil
+}
+
+//bindRecords binds the records decoded without their entity; it returns the LoadError of the first record which does not match the schema
+func bindRecords(entity Entity, filePath string, records []interface{}) *LoadError {
+	for i, record := range records {
+		r, ok := record.(*Record)
+		if !ok || r.Values != nil {
+			continue
+		}
+		err := r.bind(entity.Name)
+		if err != nil {
+			return &LoadError{Entity: entity.Name, FilePath: filePath, Op: "unmarshal", Record: i, Err: err}
+		}
+	}
+	return nil
+}
+
+//schemaEntity returns the entity of a schema Record
+func schemaEntity(record interface{}) (entity Entity, ok bool) {
+	r, ok := record.(*Record)
+	if !ok {
+		return
+	}
+	return LookupEntity(r.Entity)
+}
+
+//zeroValues returns the zero value of every field of the schema entity
+func zeroValues(entity Entity) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, field := range entity.Fields {
+		values[field.Name] = zeroValue(field.Type)
+	}
+	return values
+}
+
+func zeroValue(fieldType string) interface{} {
+	value, _ := decodeFieldValue(fieldType, nil)
+	return value
+}
+
+//decodeFieldValue converts a raw JSON value into the Go type of the field; null and missing values become the zero value
+func decodeFieldValue(fieldType string, raw json.RawMessage) (interface{}, error) {
+	isNull := len(raw) == 0 || string(raw) == "null"
+	var err error
+	switch fieldType {
+	case "string", "time", "text":
+		var v string
+		if !isNull {
+			err = json.Unmarshal(raw, &v)
+		}
+		return v, err
+	case "int":
+		var v int
+		if !isNull {
+			err = json.Unmarshal(raw, &v)
+		}
+		return v, err
+	case "float64":
+		var v float64
+		if !isNull {
+			err = json.Unmarshal(raw, &v)
+		}
+		return v, err
+	case "bool":
+		var v bool
+		if !isNull {
+			err = json.Unmarshal(raw, &v)
+		}
+		return v, err
+	case "[]string":
+		var v []string
+		if !isNull {
+			err = json.Unmarshal(raw, &v)
+		}
+		return v, err
+	}
+	return nil, fmt.Errorf("unsupported field type %s", fieldType)
+}
+
+//RecordForDisplay shows a schema record followed by the ID values of the records linked by each relation, keyed by the relation name
+type RecordForDisplay struct {
+	Record *Record
+	Linked map[string][]interface{}
+}
+
+func (r RecordForDisplay) MarshalJSON() ([]byte, error) {
+	entity, _ := LookupEntity(r.Record.Entity)
+	keys := []string{}
+	values := map[string]interface{}{}
+	for _, field := range entity.Fields {
+		keys = append(keys, field.Name)
+		values[field.Name] = r.Record.Values[field.Name]
+	}
+	for _, relation := range entity.Relations {
+		keys = append(keys, relation.Name)
+		values[relation.Name] = r.Linked[relation.Name]
+	}
+	return marshalOrdered(keys, values)
+}
+
+//displayRecord shows the linked records of a schema record by their IDs
+func displayRecord(record interface{}, linked map[string][]interface{}) interface{} {
+	r := record.(*Record)
+	entity, _ := LookupEntity(r.Entity)
+	linkedIDs := map[string][]interface{}{}
+	for _, relation := range entity.Relations {
+		target, _ := LookupEntity(relation.Target)
+		ids := []interface{}{}
+		for _, linkedRecord := range linked[relation.Name] {
+			idField := initFieldMap(linkedRecord)[target.IDField]
+			ids = append(ids, idField.Value(linkedRecord))
+		}
+		linkedIDs[relation.Name] = ids
+	}
+	return RecordForDisplay{Record: r, Linked: linkedIDs}
+}
+
+//marshalOrdered writes a JSON object with the keys in the given order
+func marshalOrdered(keys []string, values map[string]interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		keyBytes, _ := json.Marshal(key)
+		valueBytes, err := json.Marshal(values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(keyBytes)
+		buffer.WriteByte(':')
+		buffer.Write(valueBytes)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
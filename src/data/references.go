@@ -0,0 +1,99 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//ReferenceReport lists the broken links between tickets, users and organizations.
+//DanglingReferences are foreign keys pointing to a record which does not exist; unset foreign keys (0) are not dangling.
+type ReferenceReport struct {
+	DanglingReferences          []DanglingReference `json:"dangling_references"`
+	UnassignedTicketIDs         []string            `json:"unassigned_ticket_ids"`
+	OrganizationIDsWithoutUsers []int               `json:"organization_ids_without_users"`
+}
+
+//DanglingReference is a foreign key of a record, ex. ticket assignee_id, whose target record is not found
+type DanglingReference struct {
+	Entity       string `json:"entity"`
+	RecordID     string `json:"record_id"`
+	Field        string `json:"field"`
+	TargetEntity string `json:"target_entity"`
+	TargetID     int    `json:"target_id"`
+}
+
+//HasDanglingReferences returns true when any foreign key points nowhere
+func (r *ReferenceReport) HasDanglingReferences() bool {
+	return r != nil && len(r.DanglingReferences) != 0
+}
+
+//Text returns the human readable report
+func (r *ReferenceReport) Text() string {
+	lines := []string{"Reference check report", "======================"}
+	lines = append(lines, fmt.Sprintf("%d dangling references", len(r.DanglingReferences)))
+	for _, reference := range r.DanglingReferences {
+		lines = append(lines, fmt.Sprintf("  %s %s: %s %d not found in %s", reference.Entity, reference.RecordID, reference.Field, reference.TargetID, reference.TargetEntity))
+	}
+	lines = append(lines, fmt.Sprintf("%d tickets without an assignee", len(r.UnassignedTicketIDs)))
+	if len(r.UnassignedTicketIDs) != 0 {
+		lines = append(lines, "  "+strings.Join(r.UnassignedTicketIDs, ", "))
+	}
+	lines = append(lines, fmt.Sprintf("%d organizations without users", len(r.OrganizationIDsWithoutUsers)))
+	if len(r.OrganizationIDsWithoutUsers) != 0 {
+		ids := make([]string, len(r.OrganizationIDsWithoutUsers))
+		for i, id := range r.OrganizationIDsWithoutUsers {
+			ids[i] = strconv.Itoa(id)
+		}
+		lines = append(lines, "  "+strings.Join(ids, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+//CheckReferences func walks every foreign key (ticket submitter_id, assignee_id and organization_id, user organization_id) and reports the dangling ones,
+//along with the tickets without an assignee and the organizations without any user. The report follows the order of the records in the data files.
+func CheckReferences(tickets []*Ticket, users []*User, organizations []*Organization) *ReferenceReport {
+	report := &ReferenceReport{DanglingReferences: []DanglingReference{}, UnassignedTicketIDs: []string{}, OrganizationIDsWithoutUsers: []int{}}
+	userIDs := map[int]bool{}
+	for _, user := range users {
+		userIDs[user.ID] = true
+	}
+	organizationUsers := map[int]int{}
+	for _, organization := range organizations {
+		organizationUsers[organization.ID] = 0
+	}
+
+	checkReference := func(entity, recordID, field, targetEntity string, targetID int, targetIDs func(int) bool) {
+		if targetID != 0 && !targetIDs(targetID) {
+			report.DanglingReferences = append(report.DanglingReferences, DanglingReference{Entity: entity, RecordID: recordID, Field: field, TargetEntity: targetEntity, TargetID: targetID})
+		}
+	}
+	isUser := func(id int) bool {
+		return userIDs[id]
+	}
+	isOrganization := func(id int) bool {
+		_, ok := organizationUsers[id]
+		return ok
+	}
+
+	for _, ticket := range tickets {
+		checkReference("tickets", ticket.ID, "submitter_id", "users", ticket.SubmitterID, isUser)
+		checkReference("tickets", ticket.ID, "assignee_id", "users", ticket.AssigneeID, isUser)
+		checkReference("tickets", ticket.ID, "organization_id", "organizations", ticket.OrganizationID, isOrganization)
+		if ticket.AssigneeID == 0 {
+			report.UnassignedTicketIDs = append(report.UnassignedTicketIDs, ticket.ID)
+		}
+	}
+	for _, user := range users {
+		checkReference("users", strconv.Itoa(user.ID), "organization_id", "organizations", user.OrganizationID, isOrganization)
+		if isOrganization(user.OrganizationID) {
+			organizationUsers[user.OrganizationID]++
+		}
+	}
+	for _, organization := range organizations {
+		if organizationUsers[organization.ID] == 0 {
+			report.OrganizationIDsWithoutUsers = append(report.OrganizationIDsWithoutUsers, organization.ID)
+		}
+	}
+	return report
+}
@@ -0,0 +1,43 @@
+package data_test
+
+import (
+	"reflect"
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestCheckReferences(t *testing.T) {
+	tickets := []*data.Ticket{
+		{ID: "t1", SubmitterID: 1, AssigneeID: 2, OrganizationID: 101},
+		{ID: "t2", SubmitterID: 3, OrganizationID: 999},
+		{ID: "t3", SubmitterID: 1, AssigneeID: 4},
+	}
+	users := []*data.User{
+		{ID: 1, OrganizationID: 101},
+		{ID: 2, OrganizationID: 888},
+		{ID: 4},
+	}
+	organizations := []*data.Organization{
+		{ID: 101},
+		{ID: 102},
+	}
+	report := data.CheckReferences(tickets, users, organizations)
+
+	expectedDanglingReferences := []data.DanglingReference{
+		{Entity: "tickets", RecordID: "t2", Field: "submitter_id", TargetEntity: "users", TargetID: 3},
+		{Entity: "tickets", RecordID: "t2", Field: "organization_id", TargetEntity: "organizations", TargetID: 999},
+		{Entity: "users", RecordID: "2", Field: "organization_id", TargetEntity: "organizations", TargetID: 888},
+	}
+	if !reflect.DeepEqual(expectedDanglingReferences, report.DanglingReferences) {
+		t.Errorf("Expected dangling references are <%+v>, but actual references are <%+v>", expectedDanglingReferences, report.DanglingReferences)
+	}
+	if !reflect.DeepEqual([]string{"t2"}, report.UnassignedTicketIDs) {
+		t.Errorf("Expected unassigned tickets are <[t2]>, but actual tickets are <%v>", report.UnassignedTicketIDs)
+	}
+	if !reflect.DeepEqual([]int{102}, report.OrganizationIDsWithoutUsers) {
+		t.Errorf("Expected organizations without users are <[102]>, but actual organizations are <%v>", report.OrganizationIDsWithoutUsers)
+	}
+	if !report.HasDanglingReferences() {
+		t.Errorf("Expected the report has dangling references, but actually not")
+	}
+}
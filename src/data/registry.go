@@ -0,0 +1,196 @@
+package data
+
+import (
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"searchDemo/src/config"
+	"strings"
+	"sync"
+)
+
+//Entity describes a resource which is loaded from its own data file and searched, ex. tickets.
+//Name is the struct map key, the result key and the data file label; NewRecord returns a pointer to an empty record, ex. &Ticket{}.
+//File is the data file name in the data directory, <Name>.json when empty. IDField is the field map key of the record ID (ex. "id").
+//Fields are only set for schema entities, whose records are generic Record values; the fields of the built-in entities come from their structs.
+//Display turns a matched record into the value shown in the results, given the records linked by each relation; the record itself is shown when Display is nil.
+//Facets are the field map keys counted by a facets clause which names no field, ex. status and tags for the tickets.
+type Entity struct {
+	Name      string
+	Title     string
+	NewRecord func() interface{}
+	File      string
+	IDField   string
+	Fields    []FieldDefinition
+	Relations []Relation
+	Display   func(record interface{}, linked map[string][]interface{}) interface{}
+	Facets    []string
+}
+
+//Relation links the records of an entity to the records of Target whose TargetField value equals the record's Field value.
+//Field and TargetField are field map keys, ex. a ticket's submitter is {Name: "submitter", Field: "submitterid", Target: "users", TargetField: "id"},
+//and an organization's users are {Name: "users", Field: "id", Target: "users", TargetField: "organizationid"}.
+type Relation struct {
+	Name        string
+	Field       string
+	Target      string
+	TargetField string
+}
+
+var (
+	entitiesLock sync.RWMutex
+	entities     = []Entity{}
+)
+
+//RegisterEntity adds a resource to the registry. The data files, struct map, search menus and result keys are generated from the registered entities,
+//in registration order; tickets, users and organizations are registered by default. It panics when the name is already registered.
+func RegisterEntity(entity Entity) {
+	entitiesLock.Lock()
+	defer entitiesLock.Unlock()
+	for _, e := range entities {
+		if e.Name == entity.Name {
+			panic(fmt.Sprintf("data: entity %s is already registered", entity.Name))
+		}
+	}
+	if len(entity.File) == 0 {
+		entity.File = entity.Name + ".json"
+	}
+	if len(entity.Title) == 0 {
+		entity.Title = strings.ToUpper(entity.Name[:1]) + entity.Name[1:]
+	}
+	//The snapshot stores the records as interface values, which gob can only decode for registered types
+	gob.Register(entity.NewRecord())
+	config.RegisterEntityFile(entity.Name)
+	entities = append(entities, entity)
+}
+
+//Entities returns the registered entities in registration order
+func Entities() []Entity {
+	entitiesLock.RLock()
+	defer entitiesLock.RUnlock()
+	return append([]Entity{}, entities...)
+}
+
+//LookupEntity returns the registered entity with the given name
+func LookupEntity(name string) (entity Entity, ok bool) {
+	entitiesLock.RLock()
+	defer entitiesLock.RUnlock()
+	for _, e := range entities {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return
+}
+
+//Dataset holds the loaded records of every entity, keyed by the entity name
+type Dataset map[string][]interface{}
+
+//NewDataset builds the dataset of the built-in entities
+func NewDataset(tickets []*Ticket, users []*User, organizations []*Organization) Dataset {
+	dataset := Dataset{}
+	for _, record := range tickets {
+		dataset["tickets"] = append(dataset["tickets"], record)
+	}
+	for _, record := range users {
+		dataset["users"] = append(dataset["users"], record)
+	}
+	for _, record := range organizations {
+		dataset["organizations"] = append(dataset["organizations"], record)
+	}
+	return dataset
+}
+
+//Tickets returns the loaded tickets
+func (d Dataset) Tickets() (tickets []*Ticket) {
+	for _, record := range d["tickets"] {
+		tickets = append(tickets, record.(*Ticket))
+	}
+	return
+}
+
+//Users returns the loaded users
+func (d Dataset) Users() (users []*User) {
+	for _, record := range d["users"] {
+		users = append(users, record.(*User))
+	}
+	return
+}
+
+//Organizations returns the loaded organizations
+func (d Dataset) Organizations() (organizations []*Organization) {
+	for _, record := range d["organizations"] {
+		organizations = append(organizations, record.(*Organization))
+	}
+	return
+}
+
+//Linked returns the records linked to the record by each relation of the entity, keyed by the relation name
+func (e Entity) Linked(record interface{}, structMap map[string]map[string]Field) map[string][]interface{} {
+	linked := map[string][]interface{}{}
+	for _, relation := range e.Relations {
+		targetField := structMap[relation.Target][relation.TargetField]
+		for _, value := range structMap[e.Name][relation.Field].Values(record) {
+			linked[relation.Name] = append(linked[relation.Name], targetField.ValueMap[value]...)
+		}
+	}
+	return linked
+}
+
+//Relation returns the relation of the entity with the given name; the name is compared like a field key, ex. submitted_tickets is the submittedTickets relation
+func (e Entity) Relation(name string) (Relation, bool) {
+	for _, relation := range e.Relations {
+		if FieldKey(relation.Name) == FieldKey(name) {
+			return relation, true
+		}
+	}
+	return Relation{}, false
+}
+
+//LinkedTo returns the records of the entity which the relation links to any of the target records, the reverse of Linked,
+//ex. the tickets whose organization relation links to one of the given organizations. The records are looked up in the index of the relation field.
+func (e Entity) LinkedTo(relation Relation, targets []interface{}, structMap map[string]map[string]Field) (records []interface{}) {
+	field := structMap[e.Name][relation.Field]
+	targetField := structMap[relation.Target][relation.TargetField]
+	isAdded := map[interface{}]bool{}
+	for _, target := range targets {
+		for _, value := range targetField.Values(target) {
+			for _, record := range field.ValueMap[value] {
+				if !isAdded[record] {
+					isAdded[record] = true
+					records = append(records, record)
+				}
+			}
+		}
+	}
+	return
+}
+
+//Value returns the value of this field in the record, either a built-in record struct or a schema Record
+func (f Field) Value(record interface{}) interface{} {
+	r, ok := record.(*Record)
+	if ok {
+		return r.Values[f.NameWithCase]
+	}
+	v := reflect.ValueOf(record).Elem().FieldByName(f.NameWithCase)
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+//Values returns the value map keys of the record for this field, one key per element for list fields
+func (f Field) Values(record interface{}) (values []string) {
+	value := f.Value(record)
+	if value == nil {
+		return
+	}
+	list, ok := value.([]string)
+	if ok {
+		for _, element := range list {
+			values = append(values, strings.ToLower(element))
+		}
+		return
+	}
+	return []string{strings.ToLower(fmt.Sprintf("%v", value))}
+}
@@ -0,0 +1,74 @@
+package data_test
+
+import (
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"testing"
+)
+
+func TestEntities(t *testing.T) {
+	expectedNames := []string{"tickets", "users", "organizations"}
+	entities := data.Entities()
+	if len(entities) != len(expectedNames) {
+		t.Fatalf("Expected <%d> registered entities, but actual entities are <%v>", len(expectedNames), entities)
+	}
+	for i, entity := range entities {
+		if entity.Name != expectedNames[i] {
+			t.Errorf("Expected entity <%d> is <%s>, but actual entity is <%s>", i, expectedNames[i], entity.Name)
+		}
+		if entity.File != expectedNames[i]+".json" {
+			t.Errorf("Expected the data file of <%s> defaults to <%s.json>, but actual file is <%s>", entity.Name, entity.Name, entity.File)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering tickets twice panics, but actually not")
+		}
+	}()
+	data.RegisterEntity(data.Entity{Name: "tickets", NewRecord: func() interface{} { return &data.Ticket{} }})
+}
+
+func TestEntityLinked(t *testing.T) {
+	testCases := map[string]struct {
+		entity           string
+		record           interface{}
+		expectedRelation string
+		expectedLinked   []interface{}
+	}{
+		"ticket links its submitter": {
+			entity:           "tickets",
+			record:           mock.MockTickets[0],
+			expectedRelation: "submitter",
+			expectedLinked:   []interface{}{mock.MockUsers[0]},
+		},
+		"user links the tickets assigned to it": {
+			entity:           "users",
+			record:           mock.MockUsers[0],
+			expectedRelation: "assignedTickets",
+			expectedLinked:   []interface{}{mock.MockTickets[1]},
+		},
+		"organization links all its users": {
+			entity:           "organizations",
+			record:           mock.MockOrganizations[0],
+			expectedRelation: "users",
+			expectedLinked:   []interface{}{mock.MockUsers[0], mock.MockUsers[1]},
+		},
+	}
+	for tc, tp := range testCases {
+		entity, ok := data.LookupEntity(tp.entity)
+		if !ok {
+			t.Fatalf("For test case <%s>, Expected entity <%s> is registered, but actually not", tc, tp.entity)
+		}
+		linked := entity.Linked(tp.record, mock.MockStructMap)[tp.expectedRelation]
+		if len(linked) != len(tp.expectedLinked) {
+			t.Errorf("For test case <%s>, Expected <%d> linked records, but actual linked records are <%v>", tc, len(tp.expectedLinked), linked)
+			continue
+		}
+		for i := range linked {
+			if linked[i] != tp.expectedLinked[i] {
+				t.Errorf("For test case <%s>, Expected linked record <%d> is <%v>, but actually is <%v>", tc, i, tp.expectedLinked[i], linked[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+//schemaTypes maps the field types of a schema file to the Go type names used by the struct map, ex. the search prompt shows list fields as []string
+var schemaTypes = map[string]string{
+	"string": "string",
+	"int":    "int",
+	"float":  "float64",
+	"bool":   "bool",
+	"list":   "[]string",
+	"time":   "time",
+	"text":   "text",
+}
+
+//Schema describes entities which are loaded as generic records from their data files, so new exports can be searched without recompiling
+type Schema struct {
+	Entities []EntityDefinition `json:"entities"`
+}
+
+//EntityDefinition describes one schema entity. File is the data file name in the data directory, <name>.json when empty.
+//IDField and the relation fields are the field names used in the data files, ex. _id or organization_id. Facets name the fields counted by a facets clause without field names.
+type EntityDefinition struct {
+	Name      string               `json:"name"`
+	Title     string               `json:"title"`
+	File      string               `json:"file"`
+	IDField   string               `json:"id_field"`
+	Fields    []FieldDefinition    `json:"fields"`
+	Relations []RelationDefinition `json:"relations"`
+	Facets    []string             `json:"facets,omitempty"`
+}
+
+//FieldDefinition is a field of the schema records; Type is one of string, int, float, bool, time (a timestamp such as created_at) or list (a list of strings, ex. tags).
+//Order optionally lists the values of a string field in their sort order, ex. low, normal, high and urgent for a priority.
+type FieldDefinition struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Boost float64  `json:"boost,omitempty"`
+	Order []string `json:"order,omitempty"`
+}
+
+//RelationDefinition links the records to the records of Target whose TargetField equals Field, see Relation
+type RelationDefinition struct {
+	Name        string `json:"name"`
+	Field       string `json:"field"`
+	Target      string `json:"target"`
+	TargetField string `json:"target_field"`
+}
+
+//LoadSchema reads a schema file; relative data file names are kept relative to the data directory
+func LoadSchema(filePath string) (schema Schema, err error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&schema)
+	if err != nil {
+		err = fmt.Errorf("invalid schema file %s: %v", filePath, err)
+	}
+	return
+}
+
+//RegisterSchema validates the schema and registers each of its entities. Relations may target the built-in entities or other entities of the same schema.
+//Nothing is registered when the schema is invalid.
+func RegisterSchema(schema Schema) error {
+	fieldKeys := map[string]map[string]bool{}
+	for _, entity := range Entities() {
+		fieldKeys[entity.Name] = entityFieldKeys(entity)
+	}
+	for _, definition := range schema.Entities {
+		if len(definition.Name) == 0 {
+			return fmt.Errorf("schema entity without a name")
+		}
+		if _, ok := fieldKeys[definition.Name]; ok {
+			return fmt.Errorf("schema entity %s is already registered", definition.Name)
+		}
+		keys := map[string]bool{}
+		for _, field := range definition.Fields {
+			_, ok := schemaTypes[field.Type]
+			if !ok {
+				return fmt.Errorf("schema entity %s field %s: unknown type %q, expected one of string, int, float, bool, time, text, list", definition.Name, field.Name, field.Type)
+			}
+			if field.Boost < 0 {
+				return fmt.Errorf("schema entity %s field %s: boost must not be negative", definition.Name, field.Name)
+			}
+			if len(field.Order) != 0 && field.Type != "string" {
+				return fmt.Errorf("schema entity %s field %s: order is only supported on string fields", definition.Name, field.Name)
+			}
+			if keys[FieldKey(field.Name)] {
+				return fmt.Errorf("schema entity %s field %s is defined twice", definition.Name, field.Name)
+			}
+			keys[FieldKey(field.Name)] = true
+		}
+		if !keys[FieldKey(definition.IDField)] {
+			return fmt.Errorf("schema entity %s: id_field %q is not a defined field", definition.Name, definition.IDField)
+		}
+		for _, facet := range definition.Facets {
+			if !keys[FieldKey(facet)] {
+				return fmt.Errorf("schema entity %s: facet %q is not a defined field", definition.Name, facet)
+			}
+		}
+		fieldKeys[definition.Name] = keys
+	}
+	for _, definition := range schema.Entities {
+		for _, relation := range definition.Relations {
+			if !fieldKeys[definition.Name][FieldKey(relation.Field)] {
+				return fmt.Errorf("schema entity %s relation %s: field %q is not a defined field", definition.Name, relation.Name, relation.Field)
+			}
+			targetKeys, ok := fieldKeys[relation.Target]
+			if !ok {
+				return fmt.Errorf("schema entity %s relation %s: target entity %q is not registered", definition.Name, relation.Name, relation.Target)
+			}
+			if !targetKeys[FieldKey(relation.TargetField)] {
+				return fmt.Errorf("schema entity %s relation %s: target field %q is not a field of %s", definition.Name, relation.Name, relation.TargetField, relation.Target)
+			}
+		}
+	}
+	for _, definition := range schema.Entities {
+		RegisterEntity(definition.entity())
+	}
+	return nil
+}
+
+func (d EntityDefinition) entity() Entity {
+	name := d.Name
+	fields := make([]FieldDefinition, len(d.Fields))
+	for i, field := range d.Fields {
+		fields[i] = FieldDefinition{Name: field.Name, Type: schemaTypes[field.Type], Boost: field.Boost, Order: field.Order}
+	}
+	facets := make([]string, len(d.Facets))
+	for i, facet := range d.Facets {
+		facets[i] = FieldKey(facet)
+	}
+	relations := make([]Relation, len(d.Relations))
+	for i, relation := range d.Relations {
+		relations[i] = Relation{Name: relation.Name, Field: FieldKey(relation.Field), Target: relation.Target, TargetField: FieldKey(relation.TargetField)}
+	}
+	return Entity{
+		Name:      name,
+		Title:     d.Title,
+		NewRecord: func() interface{} { return &Record{Entity: name} },
+		File:      filepath.FromSlash(d.File),
+		IDField:   FieldKey(d.IDField),
+		Fields:    fields,
+		Relations: relations,
+		Display:   displayRecord,
+		Facets:    facets,
+	}
+}
+
+//FieldKey returns the struct map key of a data file field name, ex. organization_id is searched as organizationid, as the fields of the built-in entities are
+func FieldKey(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+//entityFieldKeys returns the struct map keys of the entity fields
+func entityFieldKeys(entity Entity) map[string]bool {
+	keys := map[string]bool{}
+	for key := range initFieldMap(entity.NewRecord()) {
+		keys[key] = true
+	}
+	return keys
+}
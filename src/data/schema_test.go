@@ -0,0 +1,162 @@
+package data_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/config"
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestRegisterSchema(t *testing.T) {
+	testCases := map[string]struct {
+		definition           data.EntityDefinition
+		expectedErrorMessage string
+	}{
+		"unknown field type": {
+			definition:           data.EntityDefinition{Name: "groups", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "number"}}},
+			expectedErrorMessage: `schema entity groups field _id: unknown type "number", expected one of string, int, float, bool, time, text, list`,
+		},
+		"negative boost": {
+			definition:           data.EntityDefinition{Name: "groups", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "int"}, {Name: "name", Type: "text", Boost: -1}}},
+			expectedErrorMessage: "schema entity groups field name: boost must not be negative",
+		},
+		"order on a number field": {
+			definition:           data.EntityDefinition{Name: "groups", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "int", Order: []string{"1", "2"}}}},
+			expectedErrorMessage: "schema entity groups field _id: order is only supported on string fields",
+		},
+		"id field is not defined": {
+			definition:           data.EntityDefinition{Name: "groups", IDField: "id", Fields: []data.FieldDefinition{{Name: "name", Type: "string"}}},
+			expectedErrorMessage: `schema entity groups: id_field "id" is not a defined field`,
+		},
+		"facet is not defined": {
+			definition:           data.EntityDefinition{Name: "groups", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "int"}}, Facets: []string{"tags"}},
+			expectedErrorMessage: `schema entity groups: facet "tags" is not a defined field`,
+		},
+		"entity is already registered": {
+			definition:           data.EntityDefinition{Name: "users", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "int"}}},
+			expectedErrorMessage: "schema entity users is already registered",
+		},
+		"relation target is not registered": {
+			definition: data.EntityDefinition{Name: "groups", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "int"}},
+				Relations: []data.RelationDefinition{{Name: "macros", Field: "_id", Target: "macros", TargetField: "group_id"}}},
+			expectedErrorMessage: `schema entity groups relation macros: target entity "macros" is not registered`,
+		},
+		"relation target field is not defined": {
+			definition: data.EntityDefinition{Name: "groups", IDField: "_id", Fields: []data.FieldDefinition{{Name: "_id", Type: "int"}},
+				Relations: []data.RelationDefinition{{Name: "users", Field: "_id", Target: "users", TargetField: "group_id"}}},
+			expectedErrorMessage: `schema entity groups relation users: target field "group_id" is not a field of users`,
+		},
+	}
+	for tc, tp := range testCases {
+		err := data.RegisterSchema(data.Schema{Entities: []data.EntityDefinition{tp.definition}})
+		if err == nil || err.Error() != tp.expectedErrorMessage {
+			t.Errorf("For test case <%s>, Expected error message is <%s>, but actual error is <%v>", tc, tp.expectedErrorMessage, err)
+		}
+		if len(data.Entities()) != 3 {
+			t.Errorf("For test case <%s>, Expected nothing is registered by an invalid schema, but actual entities are <%d>", tc, len(data.Entities()))
+		}
+	}
+}
+
+func TestSchemaEntities(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	schema := `{"entities": [
+		{"name": "groups", "id_field": "_id", "fields": [{"name": "_id", "type": "int"}, {"name": "name", "type": "string"}, {"name": "organization_id", "type": "int"}, {"name": "tags", "type": "list"}],
+		 "relations": [{"name": "organization", "field": "organization_id", "target": "organizations", "target_field": "_id"}]},
+		{"name": "satisfaction_ratings", "title": "Satisfaction ratings", "file": "satisfaction_ratings.csv", "id_field": "_id",
+		 "fields": [{"name": "_id", "type": "int"}, {"name": "ticket_id", "type": "string"}, {"name": "score", "type": "float"}, {"name": "comment", "type": "string"}],
+		 "relations": [{"name": "ticket", "field": "ticket_id", "target": "tickets", "target_field": "_id"}]}
+	]}`
+	files := map[string]string{
+		"tickets.json":             `[{"_id": "t1", "organization_id": 101}]`,
+		"users.json":               `[{"_id": 1, "name": "Francisca"}]`,
+		"organizations.json":       `[{"_id": 101, "name": "Enthaze"}]`,
+		"groups.json":              `[{"_id": 7, "name": "Support", "organization_id": 101, "tags": ["Tier1", "EMEA"]}, {"_id": 8, "name": "Billing", "extra": true}]`,
+		"satisfaction_ratings.csv": "_id,ticket_id,score,comment\n1,t1,4.5,Quick answer\n",
+		"schema.json":              schema,
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loadedSchema, err := data.LoadSchema(filepath.Join(dir, "schema.json"))
+	if err != nil {
+		t.Fatalf("Expected the schema file is loaded, but actual error is <%v>", err)
+	}
+	err = data.RegisterSchema(loadedSchema)
+	if err != nil {
+		t.Fatalf("Expected the schema is registered, but actual error is <%v>", err)
+	}
+	defer data.UnregisterEntity("groups")
+	defer data.UnregisterEntity("satisfaction_ratings")
+
+	serializer := data.NewExtensionSerializer(data.NewStreamSerializer(), map[string]data.StreamSerializer{
+		".csv": data.NewCSVSerializer(";"),
+	})
+	snapshotFile := filepath.Join(dir, "index.snapshot")
+	dataService := data.NewService(serializer, config.Config{DataDir: dir, SnapshotFile: snapshotFile})
+	dataset, err := dataService.LoadFile()
+	if err != nil {
+		t.Fatalf("Expected the schema entities are loaded, but actual error is <%v>", err)
+	}
+	structMap, err := dataService.PrepareStructMap(dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := structMap["groups"]["organizationid"].ValueMap["101"]
+	if len(groups) != 1 || groups[0].(*data.Record).Values["name"] != "Support" {
+		t.Errorf("Expected the Support group is indexed by organization id 101, but actual records are <%v>", groups)
+	}
+	if len(structMap["groups"]["tags"].ValueMap["emea"]) != 1 || structMap["groups"]["id"].Type != "int" {
+		t.Errorf("Expected the list field tags and the int field _id of groups are indexed, but actual field map is <%v>", structMap["groups"])
+	}
+	if len(structMap["groups"]["organizationid"].Missing) != 1 || len(structMap["groups"]["organizationid"].ValueMap["0"]) != 0 {
+		t.Errorf("Expected a group without organization_id is missing the field instead of being indexed with the zero value, but actually not")
+	}
+	rating := structMap["satisfaction_ratings"]["score"].ValueMap["4.5"]
+	if len(rating) != 1 {
+		t.Fatalf("Expected the rating loaded from CSV is indexed by the float score 4.5, but actual field is <%v>", structMap["satisfaction_ratings"]["score"])
+	}
+
+	entity, _ := data.LookupEntity("satisfaction_ratings")
+	display, err := json.Marshal(entity.Display(rating[0], entity.Linked(rating[0], structMap)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDisplay := `{"_id":1,"ticket_id":"t1","score":4.5,"comment":"Quick answer","ticket":["t1"]}`
+	if string(display) != expectedDisplay {
+		t.Errorf("Expected the rating is displayed as <%s>, but actually is <%s>", expectedDisplay, string(display))
+	}
+	projection, err := json.Marshal(entity.Project(rating[0], entity.Display(rating[0], entity.Linked(rating[0], structMap)), []string{"score", "ticket.organization.name"}, structMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedProjection := `{"score":4.5,"ticket.organization.name":"Enthaze"}`
+	if string(projection) != expectedProjection {
+		t.Errorf("Expected the rating is projected to <%s>, but actually is <%s>", expectedProjection, string(projection))
+	}
+
+	err = dataService.SaveSnapshot(structMap, nil)
+	if err != nil {
+		t.Fatalf("Expected the snapshot with schema records is saved, but actual error is <%v>", err)
+	}
+	loadedStructMap, _, err := dataService.LoadSnapshot()
+	if err != nil {
+		t.Fatalf("Expected the snapshot with schema records is loaded, but actual error is <%v>", err)
+	}
+	loadedGroups := loadedStructMap["groups"]["tags"].ValueMap["tier1"]
+	if len(loadedGroups) != 1 || loadedGroups[0].(*data.Record).Values["organization_id"] != 101 {
+		t.Errorf("Expected the groups are restored from the snapshot, but actual records are <%v>", loadedGroups)
+	}
+}
@@ -1,8 +1,14 @@
 package data
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"reflect"
 )
 
 type Serializer interface {
@@ -10,6 +16,13 @@ type Serializer interface {
 	Unmarshal(dataForSerialize []byte, v interface{}) error
 }
 
+//StreamSerializer is implemented by serializers which can decode a data file record by record, instead of reading the whole file into memory first.
+//newRecord returns a pointer to an empty record to decode into, and handleRecord receives every decoded record in file order; an error from handleRecord stops the stream.
+type StreamSerializer interface {
+	Serializer
+	Stream(filePath string, newRecord func() interface{}, handleRecord func(record interface{}) error) error
+}
+
 type serializer struct{}
 
 func NewSerializer() Serializer {
@@ -17,9 +30,209 @@ func NewSerializer() Serializer {
 }
 
 func (s *serializer) ReadFile(filePath string) ([]byte, error) {
-	return ioutil.ReadFile(filePath)
+	return readFile(filePath)
 }
 
+//Unmarshal decodes the JSON array into v, then reads the array again as raw objects to find the fields missing from each record
 func (s *serializer) Unmarshal(dataForSerialize []byte, v interface{}) error {
-	return json.Unmarshal(dataForSerialize, v)
+	err := json.Unmarshal(dataForSerialize, v)
+	if err != nil {
+		return err
+	}
+	slice := reflect.ValueOf(v).Elem()
+	if slice.Kind() != reflect.Slice {
+		return nil
+	}
+	raws := []json.RawMessage{}
+	err = json.Unmarshal(dataForSerialize, &raws)
+	if err != nil || len(raws) != slice.Len() {
+		return nil
+	}
+	for i, raw := range raws {
+		record := slice.Index(i)
+		if record.Kind() != reflect.Ptr {
+			record = record.Addr()
+		}
+		trackPresence(raw, record.Interface())
+	}
+	return nil
+}
+
+type streamSerializer struct{}
+
+//NewStreamSerializer returns a StreamSerializer for large exports. It accepts either a top level JSON array or newline-delimited JSON (one object per line),
+//detected from the first non-whitespace byte of the file, and only keeps one raw record in memory at a time.
+func NewStreamSerializer() StreamSerializer {
+	return &streamSerializer{}
+}
+
+func (s *streamSerializer) ReadFile(filePath string) ([]byte, error) {
+	return readFile(filePath)
+}
+
+//Unmarshal decodes either format into v, which must be a pointer to a slice
+func (s *streamSerializer) Unmarshal(dataForSerialize []byte, v interface{}) error {
+	newRecord, handleRecord, err := sliceAppender(v)
+	if err != nil {
+		return err
+	}
+	err = decodeRecords(bytes.NewReader(dataForSerialize), newRecord, handleRecord)
+	return withPosition(err, func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(dataForSerialize)), nil
+	})
+}
+
+func (s *streamSerializer) Stream(filePath string, newRecord func() interface{}, handleRecord func(record interface{}) error) error {
+	file, err := OpenFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = decodeRecords(file, newRecord, handleRecord)
+	return withPosition(err, func() (io.ReadCloser, error) {
+		return OpenFile(filePath)
+	})
+}
+
+//recordError carries the offset of a failed record until the position is resolved into line and column by withPosition
+type recordError struct {
+	record int
+	offset int64
+	err    error
+}
+
+func (e *recordError) Error() string {
+	return e.err.Error()
+}
+
+func decodeRecords(r io.Reader, newRecord func() interface{}, handleRecord func(record interface{}) error) error {
+	reader := bufio.NewReader(r)
+	isArray, skipped, err := sniffArray(reader)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(reader)
+	if isArray {
+		//Consume the opening '[' so the decoder returns the array elements one by one
+		_, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+	}
+	for record := 0; decoder.More(); record++ {
+		//Decode into a raw message first, so a type error can be located in the file by the record's start offset
+		raw := json.RawMessage{}
+		err = decoder.Decode(&raw)
+		if err != nil {
+			offset := syntaxOffset(err)
+			if offset >= 0 {
+				offset += skipped
+			}
+			return &recordError{record: record, offset: offset, err: err}
+		}
+		start := skipped + decoder.InputOffset() - int64(len(raw))
+		v := newRecord()
+		err = json.Unmarshal(raw, v)
+		if err != nil {
+			offset := int64(-1)
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				offset = start + typeErr.Offset
+			}
+			return &recordError{record: record, offset: offset, err: err}
+		}
+		trackPresence(raw, v)
+		err = handleRecord(v)
+		if err != nil {
+			return err
+		}
+	}
+	if isArray {
+		_, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//sniffArray skips the leading whitespace and reports whether the data is a JSON array ('[') or newline-delimited JSON objects ('{').
+//skipped is the number of whitespace bytes consumed, which the decoder's offsets do not include.
+func sniffArray(reader *bufio.Reader) (isArray bool, skipped int64, err error) {
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return false, skipped, nil
+		}
+		if err != nil {
+			return false, skipped, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+			skipped++
+		case '[':
+			return true, skipped, nil
+		case '{':
+			return false, skipped, nil
+		default:
+			return false, skipped, fmt.Errorf("unsupported data format: expected a JSON array or newline-delimited JSON objects, found %q", b[0])
+		}
+	}
+}
+
+func syntaxOffset(err error) int64 {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset
+	}
+	return -1
+}
+
+//withPosition converts a recordError into a PositionError by scanning the data again up to the failed offset;
+//the data is only read a second time on failure, so a successful stream never buffers the file
+func withPosition(err error, reopen func() (io.ReadCloser, error)) error {
+	recordErr, ok := err.(*recordError)
+	if !ok {
+		return err
+	}
+	positionErr := &PositionError{Record: recordErr.record, Err: recordErr.err}
+	if recordErr.offset < 0 {
+		return positionErr
+	}
+	r, e := reopen()
+	if e != nil {
+		return positionErr
+	}
+	defer r.Close()
+	positionErr.Line, positionErr.Column, _ = locateOffset(r, recordErr.offset)
+	return positionErr
+}
+
+//sliceAppender returns the newRecord and handleRecord funcs which decode records into the slice v points to, ex. *[]*Ticket
+func sliceAppender(v interface{}) (newRecord func() interface{}, handleRecord func(record interface{}) error, err error) {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Slice {
+		err = fmt.Errorf("expected a pointer to a slice, got %T", v)
+		return
+	}
+	slice := target.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	recordType := elemType
+	if isPtr {
+		recordType = elemType.Elem()
+	}
+	newRecord = func() interface{} {
+		return reflect.New(recordType).Interface()
+	}
+	handleRecord = func(record interface{}) error {
+		value := reflect.ValueOf(record)
+		if !isPtr {
+			value = value.Elem()
+		}
+		slice.Set(reflect.Append(slice, value))
+		return nil
+	}
+	return
 }
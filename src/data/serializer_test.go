@@ -0,0 +1,117 @@
+package data_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestStreamSerializer(t *testing.T) {
+	testCases := map[string]struct {
+		content              string
+		expectedIDs          []int
+		expectedHasError     bool
+		expectedErrorRecord  int
+		expectedErrorLine    int
+		expectedErrorColumn  int
+		expectedErrorMessage string
+	}{
+		"JSON array": {
+			content:     "\n  [\n {\"_id\": 1, \"name\": \"org1\"},\n {\"_id\": 2}\n]\n",
+			expectedIDs: []int{1, 2},
+		},
+		"newline-delimited JSON": {
+			content:     "{\"_id\": 1, \"name\": \"org1\"}\n\n{\"_id\": 2}\n{\"_id\": 3}",
+			expectedIDs: []int{1, 2, 3},
+		},
+		"empty file": {
+			content:     "  \n",
+			expectedIDs: []int{},
+		},
+		"type error in a JSON array": {
+			content:             "[\n {\"_id\": 1},\n {\"_id\": \"two\"}\n]",
+			expectedHasError:    true,
+			expectedErrorRecord: 1,
+			expectedErrorLine:   3,
+			expectedErrorColumn: 14,
+		},
+		"syntax error in newline-delimited JSON": {
+			content:             "{\"_id\": 1}\n{\"_id\": 2,}\n",
+			expectedHasError:    true,
+			expectedErrorRecord: 1,
+			expectedErrorLine:   2,
+			expectedErrorColumn: 11,
+		},
+		"unsupported format": {
+			content:              "_id,name\n1,org1\n",
+			expectedHasError:     true,
+			expectedErrorMessage: "unsupported data format: expected a JSON array or newline-delimited JSON objects, found '_'",
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "serializer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for tc, tp := range testCases {
+		filePath := filepath.Join(dir, "organizations.json")
+		err = ioutil.WriteFile(filePath, []byte(tp.content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		organizations := []*data.Organization{}
+		streamSerializer := data.NewStreamSerializer()
+		err = streamSerializer.Stream(filePath, func() interface{} {
+			return &data.Organization{}
+		}, func(record interface{}) error {
+			organizations = append(organizations, record.(*data.Organization))
+			return nil
+		})
+
+		//Unmarshal must decode the same content into the same records or error
+		unmarshaled := []*data.Organization{}
+		unmarshalErr := streamSerializer.Unmarshal([]byte(tp.content), &unmarshaled)
+		if (err == nil) != (unmarshalErr == nil) || len(organizations) != len(unmarshaled) {
+			t.Errorf("For test case <%s>, Expected Stream and Unmarshal return the same results, but Stream returned <%v> and Unmarshal returned <%v>", tc, err, unmarshalErr)
+		}
+
+		if !tp.expectedHasError {
+			if err != nil {
+				t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+				continue
+			}
+			if len(organizations) != len(tp.expectedIDs) {
+				t.Errorf("For test case <%s>, Expected <%d> records, but actually decoded <%d>", tc, len(tp.expectedIDs), len(organizations))
+				continue
+			}
+			for i, id := range tp.expectedIDs {
+				if organizations[i].ID != id {
+					t.Errorf("For test case <%s>, Expected record <%d> has id <%d>, but actual id is <%d>", tc, i, id, organizations[i].ID)
+				}
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("For test case <%s>, Expected there is an error, but actually not", tc)
+			continue
+		}
+		if len(tp.expectedErrorMessage) != 0 {
+			if err.Error() != tp.expectedErrorMessage {
+				t.Errorf("For test case <%s>, Expected error message is <%s>, but actual message is <%s>", tc, tp.expectedErrorMessage, err.Error())
+			}
+			continue
+		}
+		var positionErr *data.PositionError
+		if !errors.As(err, &positionErr) {
+			t.Errorf("For test case <%s>, Expected a PositionError, but actual error is <%v>", tc, err)
+			continue
+		}
+		if positionErr.Record != tp.expectedErrorRecord || positionErr.Line != tp.expectedErrorLine || positionErr.Column != tp.expectedErrorColumn {
+			t.Errorf("For test case <%s>, Expected error at record <%d> line <%d> column <%d>, but actual error is <%v>", tc, tp.expectedErrorRecord, tp.expectedErrorLine, tp.expectedErrorColumn, err)
+		}
+	}
+}
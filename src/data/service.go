@@ -1,72 +1,84 @@
 package data
 
 import (
-	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"searchDemo/src/config"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 type Service interface {
-	PrepareStructMap(tickets []*Ticket, users []*User, organizations []*Organization) (map[string]map[string]Field, error)
-	LoadFile() (tickets []*Ticket, users []*User, organizations []*Organization, err error)
+	PrepareStructMap(dataset Dataset) (map[string]map[string]Field, error)
+	LoadFile() (dataset Dataset, err error)
+	Validate(dataset Dataset) (report *ValidationReport, err error)
+	LoadSnapshot() (structMap map[string]map[string]Field, report *ValidationReport, err error)
+	SaveSnapshot(structMap map[string]map[string]Field, report *ValidationReport) error
+	Fingerprints() (fingerprints []SourceFingerprint, err error)
 }
 
 type service struct {
 	Serializer Serializer
+	Config     config.Config
 }
 
+//Field is a searchable field of an entity. Type is the Go type of the field, or time for the timestamp fields and text for the full-text fields;
+//Index is built from ValueMap by BuildIndexes. Boost weights the relevance score of the field, 1 when it is not set.
+//Missing holds the records which have no value or null for the field in their data file, and Blank the records with an empty string or list;
+//the missing records are left out of ValueMap, so an absent int is not found as 0. Order lists the values in their sort order when it is not alphabetical.
 type Field struct {
 	Type         string
 	NameWithCase string
 	ValueMap     map[string][]interface{}
+	Index        FieldIndex
+	Boost        float64
+	Missing      []interface{}
+	Blank        []interface{}
+	Order        []string
 }
 
-func NewService(serializer Serializer) Service {
-	return &service{Serializer: serializer}
+func NewService(serializer Serializer, cfg config.Config) Service {
+	return &service{Serializer: serializer, Config: cfg}
 }
 
-func (s *service) PrepareStructMap(tickets []*Ticket, users []*User, organizations []*Organization) (structMap map[string]map[string]Field, err error) {
-	err = validateSource(tickets, users, organizations)
+//PrepareStructMap func builds the field map of every registered entity; the struct map is keyed by the entity name, ex. tickets
+func (s *service) PrepareStructMap(dataset Dataset) (structMap map[string]map[string]Field, err error) {
+	err = validateSource(dataset)
 	if err != nil {
 		return
 	}
-
-	//Convert tickets, users and organizations slice to []interface{} so they can share the same ProcessFieldList func which takes []interface{} as param
-	tList := make([]interface{}, len(tickets))
-	for i, v := range tickets {
-		tList[i] = v
+	structMap = map[string]map[string]Field{}
+	for _, entity := range Entities() {
+		structMap[entity.Name] = ProcessFieldMap(dataset[entity.Name])
 	}
+	BuildIndexes(structMap)
+	return
+}
 
-	uList := make([]interface{}, len(users))
-	for i, v := range users {
-		uList[i] = v
+//Validate func runs the data quality checks according to the configured validation mode.
+//It returns no report when the validation is off, and an error along with the report when the mode is strict and any record fails.
+func (s *service) Validate(dataset Dataset) (report *ValidationReport, err error) {
+	if s.Config.ValidationMode == ValidationOff {
+		return
 	}
-	oList := make([]interface{}, len(organizations))
-	for i, v := range organizations {
-		oList[i] = v
+	report = Validate(dataset.Tickets(), dataset.Users(), dataset.Organizations())
+	for _, entity := range Entities() {
+		report.Records[entity.Name] = len(dataset[entity.Name])
 	}
-	structMap = map[string]map[string]Field{
-		"1": ProcessFieldMap(tList),
-		"2": ProcessFieldMap(uList),
-		"3": ProcessFieldMap(oList),
+	if s.Config.ValidationMode == ValidationStrict && report.HasFailures() {
+		err = fmt.Errorf("data validation failed with %d rule failures in strict mode", len(report.Failures))
 	}
 	return
 }
 
-func validateSource(tickets []*Ticket, users []*User, organizations []*Organization) (err error) {
-	if len(tickets) == 0 {
-		err = errors.New("The given tickets data is empty")
-		return
-	}
-	if len(users) == 0 {
-		err = errors.New("The given users data is empty")
-		return
-	}
-	if len(organizations) == 0 {
-		err = errors.New("The given organizations data is empty")
-		return
+func validateSource(dataset Dataset) (err error) {
+	for _, entity := range Entities() {
+		if len(dataset[entity.Name]) == 0 {
+			err = fmt.Errorf("The given %s data is empty", entity.Name)
+			return
+		}
 	}
 	return
 }
@@ -77,90 +89,148 @@ func validateSource(tickets []*Ticket, users []*User, organizations []*Organizat
 //The nested value map has the struct field value in string format, with lower case as the key (ex. "a drama in gabon", or "true");
 //the map value is a list of pointers to the structs which contains the map key;
 //When a field contains an string list, such as 'Tags', treat each string in the list as a separate value map key;
+//The records missing the field in their data file are kept in Missing instead of the value map, and the records with an empty value are also kept in Blank;
 func ProcessFieldMap(structList []interface{}) map[string]Field {
 	fieldMap := initFieldMap(structList[0])
 	for _, s := range structList {
-		v := reflect.ValueOf(s).Elem()
-		for k := range fieldMap {
-			//Since k is processed as lower case, we need to use fieldNameWithCase for reflect.value.FieldByName func to get the field value
-			fieldNameWithCase := fieldMap[k].NameWithCase
-			updatedPtrList := []interface{}{}
-			switch fieldMap[k].Type {
-			case "[]string":
-				list, ok := v.FieldByName(fieldNameWithCase).Interface().([]string)
-				if ok {
-					for _, element := range list {
-						//get the point list from ValueMap by the given key.
-						//If the key does not exist, it returns an empty slice; so here we don't need to have extra checks to see whether reading key is OK
-						matchedPtrList, _ := fieldMap[k].ValueMap[strings.ToLower(element)]
-						updatedPtrList = append(matchedPtrList, s)
-						fieldMap[k].ValueMap[strings.ToLower(element)] = updatedPtrList
-					}
-				}
-				break
-			default:
-				fieldValue := strings.ToLower(fmt.Sprintf("%v", v.FieldByName(fieldNameWithCase)))
-				matchedPtrList, _ := fieldMap[k].ValueMap[fieldValue]
-				updatedPtrList = append(matchedPtrList, s)
-				fieldMap[k].ValueMap[fieldValue] = updatedPtrList
+		for k, field := range fieldMap {
+			if field.IsMissing(s) {
+				field.Missing = append(field.Missing, s)
+				fieldMap[k] = field
+				continue
+			}
+			if isBlank(field.Value(s)) {
+				field.Blank = append(field.Blank, s)
 			}
+			//get the pointer list from ValueMap by the given key.
+			//If the key does not exist, it returns an empty slice; so here we don't need to have extra checks to see whether reading key is OK
+			for _, value := range field.Values(s) {
+				field.ValueMap[value] = append(field.ValueMap[value], s)
+			}
+			fieldMap[k] = field
 		}
 	}
 	return fieldMap
 }
 
 func initFieldMap(instance interface{}) map[string]Field {
-	v := reflect.ValueOf(instance).Elem()
 	fieldMap := map[string]Field{}
+	record, ok := instance.(*Record)
+	if ok {
+		//Schema records are keyed the same way, ex. the organization_id field is searched as organizationid
+		entity, _ := LookupEntity(record.Entity)
+		for _, field := range entity.Fields {
+			fieldMap[FieldKey(field.Name)] = Field{Type: field.Type, ValueMap: map[string][]interface{}{}, NameWithCase: field.Name, Boost: field.Boost, Order: field.Order}
+		}
+		return fieldMap
+	}
+	v := reflect.ValueOf(instance).Elem()
 	for i := 0; i < v.NumField(); i++ {
+		//Unexported fields, such as the missing fields of a record, are not searchable
+		if len(v.Type().Field(i).PkgPath) != 0 {
+			continue
+		}
 		f := v.Field(i)
 		//To support case insensitive search, we use field name in lower case as the key of fieldMap.
 		//We also save the original case of the field name into the Field struct, this helps when we fill in the values to this initiated FieldMap later;
 		fieldNameWithCase := v.Type().Field(i).Name
 		n := strings.ToLower(fieldNameWithCase)
 		t := f.Type().String()
-		fieldMap[n] = Field{Type: t, ValueMap: map[string][]interface{}{}, NameWithCase: fieldNameWithCase}
+		//Timestamps and long texts are stored as strings, the search tag marks them to be indexed as time or as full text
+		tag := v.Type().Field(i).Tag.Get("search")
+		if tag == "time" || tag == "text" {
+			t = tag
+		}
+		//The boost tag ranks the matches of a field above the other fields, ex. the ticket subject above the description
+		boost, _ := strconv.ParseFloat(v.Type().Field(i).Tag.Get("boost"), 64)
+		//The order tag sorts the values of a field by their meaning instead of alphabetically, ex. the ticket priority from low to urgent
+		var order []string
+		if tag := v.Type().Field(i).Tag.Get("order"); len(tag) != 0 {
+			order = strings.Split(tag, ",")
+		}
+		fieldMap[n] = Field{Type: t, ValueMap: map[string][]interface{}{}, NameWithCase: fieldNameWithCase, Boost: boost, Order: order}
 	}
 	return fieldMap
 }
 
-func (s *service) LoadFile() (tickets []*Ticket, users []*User, organizations []*Organization, err error) {
+//LoadFile func reads and unmarshals the data file of every registered entity concurrently.
+//Every failed file is reported: the returned error is a LoadErrors listing one LoadError per failed entity, in registration order.
+func (s *service) LoadFile() (dataset Dataset, err error) {
 	var wg sync.WaitGroup
-	loadStructs := []struct {
-		label  string
-		target interface{}
-	}{
-		{label: "tickets", target: &tickets},
-		{label: "users", target: &users},
-		{label: "organizations", target: &organizations},
-	}
-	errsChan := make(chan error, len(loadStructs))
-	wg.Add(len(loadStructs))
-	for _, loadStruct := range loadStructs {
-		go func(loadStruct struct {
-			label  string
-			target interface{}
-		}) {
+	loadEntities := Entities()
+	//Each goroutine only writes its own slot, so the records and errors can be collected without a lock and keep a stable order
+	records := make([][]interface{}, len(loadEntities))
+	loadErrs := make([]*LoadError, len(loadEntities))
+	wg.Add(len(loadEntities))
+	for i, entity := range loadEntities {
+		go func(i int, entity Entity) {
 			defer wg.Done()
-			data, e := s.Serializer.ReadFile(fmt.Sprintf("./data/%s.json", loadStruct.label))
+			label := entity.Name
+			filePath := s.filePath(entity)
+			streamSerializer, ok := s.Serializer.(StreamSerializer)
+			if ok {
+				e := streamSerializer.Stream(filePath, entity.NewRecord, func(record interface{}) error {
+					records[i] = append(records[i], record)
+					return nil
+				})
+				if e != nil {
+					loadErrs[i] = newStreamError(label, filePath, e)
+				}
+				return
+			}
+			target := reflect.New(reflect.SliceOf(reflect.TypeOf(entity.NewRecord())))
+			data, e := s.Serializer.ReadFile(filePath)
 			if e != nil {
-				err = fmt.Errorf("read %s.json file failed", loadStruct.label)
-				errsChan <- err
+				loadErrs[i] = &LoadError{Entity: label, FilePath: filePath, Op: "read", Record: -1, Err: e}
 				return
 			}
-			e = s.Serializer.Unmarshal(data, loadStruct.target)
+			e = s.Serializer.Unmarshal(data, target.Interface())
 			if e != nil {
-				err = fmt.Errorf("unmarshal %s failed", loadStruct.label)
-				errsChan <- err
+				loadErrs[i] = newUnmarshalError(label, filePath, data, e)
 				return
 			}
-		}(loadStruct)
+			records[i] = toRecords(target.Elem())
+			loadErrs[i] = bindRecords(entity, filePath, records[i])
+		}(i, entity)
 	}
 	wg.Wait()
-	close(errsChan)
-	for e := range errsChan {
-		err = e
-		break
+
+	failures := LoadErrors{}
+	dataset = Dataset{}
+	for i, loadErr := range loadErrs {
+		if loadErr != nil {
+			failures = append(failures, loadErr)
+			continue
+		}
+		dataset[loadEntities[i].Name] = records[i]
+	}
+	if len(failures) != 0 {
+		err = failures
 	}
 	return
 }
+
+//toRecords converts a typed record slice, ex. []*Ticket, to the []interface{} kept in the dataset
+func toRecords(slice reflect.Value) []interface{} {
+	records := make([]interface{}, slice.Len())
+	for i := range records {
+		records[i] = slice.Index(i).Interface()
+	}
+	return records
+}
+
+//filePath returns the data file of the entity. When the file is not configured explicitly and <data dir>/<entity file> does not exist,
+//a compressed copy such as tickets.json.gz is used instead
+func (s *service) filePath(entity Entity) string {
+	filePath := s.Config.EntityFilePath(entity.Name, entity.File)
+	if len(s.Config.Files[entity.Name]) != 0 {
+		return filePath
+	}
+	for _, path := range compressedPaths(filePath) {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path
+		}
+	}
+	return filePath
+}
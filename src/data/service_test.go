@@ -1,7 +1,12 @@
 package data_test
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/config"
 	"searchDemo/src/data"
 	"searchDemo/src/mock"
 	"strings"
@@ -16,35 +21,36 @@ func TestFailedLoadFileError(t *testing.T) {
 	}{
 		"tickets: read file failed": {
 			failLoadedFileName:   "tickets",
-			expectedErrorMessage: "read tickets.json file failed",
+			expectedErrorMessage: "read tickets file data/tickets.json failed: read error",
 		},
 		"users: read file failed": {
 			failLoadedFileName:   "users",
-			expectedErrorMessage: "read users.json file failed",
+			expectedErrorMessage: "read users file data/users.json failed: read error",
 		},
 		"organizations: read file failed": {
 			failLoadedFileName:   "organizations",
-			expectedErrorMessage: "read organizations.json file failed",
+			expectedErrorMessage: "read organizations file data/organizations.json failed: read error",
 		},
 		"tickets: unmarshal failed": {
 			failUnMarshaledStructName: "tickets",
-			expectedErrorMessage:      "unmarshal tickets failed",
+			expectedErrorMessage:      "unmarshal tickets file data/tickets.json failed: unmarshal error",
 		},
 		"users: unmarshal failed": {
 			failUnMarshaledStructName: "users",
-			expectedErrorMessage:      "unmarshal users failed",
+			expectedErrorMessage:      "unmarshal users file data/users.json failed: unmarshal error",
 		},
 		"organizations: unmarshal failed": {
 			failUnMarshaledStructName: "organizations",
-			expectedErrorMessage:      "unmarshal organizations failed",
+			expectedErrorMessage:      "unmarshal organizations file data/organizations.json failed: unmarshal error",
 		},
 	}
 	for tc, tp := range testCases {
 		mockSerializer := &mockSerializer{failLoadedFileName: tp.failLoadedFileName, failUnMarshaledStructName: tp.failUnMarshaledStructName}
-		dataService := data.NewService(mockSerializer)
-		_, _, _, err := dataService.LoadFile()
+		dataService := data.NewService(mockSerializer, config.Default())
+		_, err := dataService.LoadFile()
 		if err == nil {
 			t.Errorf("For test case <%s>, Expected there is an error, but actually not", tc)
+			continue
 		}
 		if err.Error() != tp.expectedErrorMessage {
 			t.Errorf("For test case <%s>, Expected error message is: <%s>, but actual message is: <%s>", tc, tp.expectedErrorMessage, err.Error())
@@ -52,6 +58,52 @@ func TestFailedLoadFileError(t *testing.T) {
 	}
 }
 
+func TestLoadFileReportsAllErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"tickets":       "[\n  {\"_id\": \"t1\"},\n  {\"_id\": \"t2\", \"submitter_id\": \"abc\"}\n]",
+		"organizations": "[\n  {\"_id\": 1},\n  {\"_id\": 2,,}\n]",
+	}
+	for label, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, label+".json"), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	dataService := data.NewService(data.NewSerializer(), config.Config{DataDir: dir})
+	_, err = dataService.LoadFile()
+
+	var loadErrs data.LoadErrors
+	if !errors.As(err, &loadErrs) {
+		t.Fatalf("Expected the error is a LoadErrors, but actual error is <%v>", err)
+	}
+	expectedErrors := []data.LoadError{
+		{Entity: "tickets", Op: "unmarshal", Line: 3, Column: 37, Record: 1},
+		{Entity: "users", Op: "read", Record: -1},
+		{Entity: "organizations", Op: "unmarshal", Line: 3, Column: 13, Record: 1},
+	}
+	if len(loadErrs) != len(expectedErrors) {
+		t.Fatalf("Expected <%d> load errors, but actual errors are <%v>", len(expectedErrors), err)
+	}
+	for i, expected := range expectedErrors {
+		actual := loadErrs[i]
+		if actual.Entity != expected.Entity || actual.Op != expected.Op || actual.Line != expected.Line || actual.Column != expected.Column || actual.Record != expected.Record {
+			t.Errorf("For error <%d>, Expected <%s %s line %d column %d record %d>, but actual error is <%v>", i, expected.Op, expected.Entity, expected.Line, expected.Column, expected.Record, actual)
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the missing users file is reported as os.ErrNotExist, but actual error is <%v>", err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || !strings.HasSuffix(typeErr.Field, "submitter_id") {
+		t.Errorf("Expected the tickets error wraps a json.UnmarshalTypeError on submitter_id, but actual error is <%v>", err)
+	}
+}
+
 type mockSerializer struct {
 	failLoadedFileName        string
 	failUnMarshaledStructName string
@@ -122,8 +174,8 @@ func TestPrepareStructMap(t *testing.T) {
 	}
 	for tc, tp := range testCases {
 		mockSerializer := &mockSerializer{}
-		dataService := data.NewService(mockSerializer)
-		structMap, err := dataService.PrepareStructMap(tp.tickets, tp.users, tp.organizations)
+		dataService := data.NewService(mockSerializer, config.Default())
+		structMap, err := dataService.PrepareStructMap(data.NewDataset(tp.tickets, tp.users, tp.organizations))
 		if tp.hasError {
 			if err == nil {
 				t.Errorf("For test case <%s>, Expected error returned but Actually not", tc)
@@ -0,0 +1,276 @@
+package data
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//snapshotMagic starts every snapshot file, followed by the format version, the SHA-256 checksum of the payload and the gob encoded payload
+const snapshotMagic = "SDSNAP"
+
+//snapshotVersion must be increased whenever the snapshot payload or the struct map layout changes, so older snapshots are rebuilt instead of misread
+const snapshotVersion uint32 = 8
+
+var (
+	//ErrSnapshotDisabled is returned when no snapshot file is configured
+	ErrSnapshotDisabled = errors.New("index snapshot is disabled")
+	//ErrSnapshotStale is returned when the data files changed since the snapshot was written
+	ErrSnapshotStale = errors.New("index snapshot is out of date")
+	//ErrSnapshotValidationMode is returned when the snapshot was validated in another validation mode, ex. saved by a lenient run and loaded by a strict one
+	ErrSnapshotValidationMode = fmt.Errorf("%w: it was saved in another validation mode", ErrSnapshotStale)
+)
+
+//SourceFingerprint identifies the version of a data file by its path, size and modification time
+type SourceFingerprint struct {
+	Entity  string
+	Path    string
+	Size    int64
+	ModTime int64
+}
+
+//snapshotPayload stores the records of each entity and the struct map with record positions instead of pointers, as gob does not keep pointer identity.
+//ValidationMode is the mode the validation report was made in, as a report which passed a lenient run may fail a strict one.
+type snapshotPayload struct {
+	Sources          []SourceFingerprint
+	ValidationMode   string
+	Records          map[string][]interface{}
+	Fields           map[string]map[string]snapshotField
+	ValidationReport *ValidationReport
+}
+
+type snapshotField struct {
+	Type         string
+	NameWithCase string
+	Positions    map[string][]int
+	Missing      []int
+	Blank        []int
+	Boost        float64
+	Order        []string
+}
+
+//Fingerprints returns the current fingerprint of every data file
+func (s *service) Fingerprints() (fingerprints []SourceFingerprint, err error) {
+	for _, entity := range Entities() {
+		filePath := s.filePath(entity)
+		info, e := os.Stat(filePath)
+		if e != nil {
+			return nil, e
+		}
+		fingerprints = append(fingerprints, SourceFingerprint{Entity: entity.Name, Path: filePath, Size: info.Size(), ModTime: info.ModTime().UnixNano()})
+	}
+	return
+}
+
+//SaveSnapshot func writes the struct map and the validation report to the configured snapshot file, along with the fingerprints of the data files it was built from.
+//The file is written to a temporary file first and renamed, so a crash never leaves a half written snapshot.
+func (s *service) SaveSnapshot(structMap map[string]map[string]Field, report *ValidationReport) (err error) {
+	if len(s.Config.SnapshotFile) == 0 {
+		return ErrSnapshotDisabled
+	}
+	payload := snapshotPayload{Records: map[string][]interface{}{}, Fields: map[string]map[string]snapshotField{}, ValidationReport: report, ValidationMode: s.Config.ValidationMode}
+	payload.Sources, err = s.Fingerprints()
+	if err != nil {
+		return
+	}
+	positions := map[interface{}]int{}
+	for structKey, fieldMap := range structMap {
+		for _, record := range collectRecords(fieldMap) {
+			positions[record] = len(payload.Records[structKey])
+			payload.Records[structKey] = append(payload.Records[structKey], record)
+		}
+	}
+	for structKey, fieldMap := range structMap {
+		payload.Fields[structKey] = map[string]snapshotField{}
+		for fieldKey, field := range fieldMap {
+			sf := snapshotField{Type: field.Type, NameWithCase: field.NameWithCase, Positions: map[string][]int{}, Boost: field.Boost, Order: field.Order}
+			for value, records := range field.ValueMap {
+				recordPositions := make([]int, len(records))
+				for i, record := range records {
+					recordPositions[i] = positions[record]
+				}
+				sf.Positions[value] = recordPositions
+			}
+			sf.Missing = positionsOf(field.Missing, positions)
+			sf.Blank = positionsOf(field.Blank, positions)
+			payload.Fields[structKey][fieldKey] = sf
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	err = gob.NewEncoder(buffer).Encode(payload)
+	if err != nil {
+		return
+	}
+	checksum := sha256.Sum256(buffer.Bytes())
+	header := &bytes.Buffer{}
+	header.WriteString(snapshotMagic)
+	binary.Write(header, binary.BigEndian, snapshotVersion)
+	header.Write(checksum[:])
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(s.Config.SnapshotFile), filepath.Base(s.Config.SnapshotFile)+".tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(append(header.Bytes(), buffer.Bytes()...))
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	closeErr := tempFile.Close()
+	if err != nil {
+		return
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	return os.Rename(tempFile.Name(), s.Config.SnapshotFile)
+}
+
+//LoadSnapshot func reads the struct map back from the configured snapshot file. It returns ErrSnapshotStale when the data files changed since the snapshot was written
+//or when it was saved in another validation mode, so the data is validated again in the current mode, and an error when the snapshot is from another format version or fails the checksum; in both cases the caller is expected to rebuild the struct map.
+func (s *service) LoadSnapshot() (structMap map[string]map[string]Field, report *ValidationReport, err error) {
+	if len(s.Config.SnapshotFile) == 0 {
+		err = ErrSnapshotDisabled
+		return
+	}
+	content, err := ioutil.ReadFile(s.Config.SnapshotFile)
+	if err != nil {
+		return
+	}
+	headerLength := len(snapshotMagic) + 4 + sha256.Size
+	if len(content) < headerLength || string(content[:len(snapshotMagic)]) != snapshotMagic {
+		err = fmt.Errorf("%s is not an index snapshot", s.Config.SnapshotFile)
+		return
+	}
+	version := binary.BigEndian.Uint32(content[len(snapshotMagic):])
+	if version != snapshotVersion {
+		err = fmt.Errorf("index snapshot version %d is not supported, expected version %d", version, snapshotVersion)
+		return
+	}
+	payloadBytes := content[headerLength:]
+	checksum := sha256.Sum256(payloadBytes)
+	if !bytes.Equal(checksum[:], content[len(snapshotMagic)+4:headerLength]) {
+		err = fmt.Errorf("index snapshot %s is corrupted: checksum mismatch", s.Config.SnapshotFile)
+		return
+	}
+	payload := snapshotPayload{}
+	err = gob.NewDecoder(bytes.NewReader(payloadBytes)).Decode(&payload)
+	if err != nil {
+		return
+	}
+
+	fingerprints, err := s.Fingerprints()
+	if err != nil {
+		return
+	}
+	if !SameFingerprints(fingerprints, payload.Sources) {
+		err = ErrSnapshotStale
+		return
+	}
+	if payload.ValidationMode != s.Config.ValidationMode {
+		err = ErrSnapshotValidationMode
+		return
+	}
+
+	structMap = map[string]map[string]Field{}
+	for structKey, fields := range payload.Fields {
+		structMap[structKey] = map[string]Field{}
+		for fieldKey, sf := range fields {
+			field := Field{Type: sf.Type, NameWithCase: sf.NameWithCase, ValueMap: map[string][]interface{}{}, Boost: sf.Boost, Order: sf.Order}
+			for value, recordPositions := range sf.Positions {
+				records := make([]interface{}, len(recordPositions))
+				for i, position := range recordPositions {
+					records[i], err = payload.record(structKey, position)
+					if err != nil {
+						return nil, nil, err
+					}
+				}
+				field.ValueMap[value] = records
+			}
+			field.Missing, err = payload.records(structKey, sf.Missing)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, record := range field.Missing {
+				markMissing(record, field.NameWithCase)
+			}
+			field.Blank, err = payload.records(structKey, sf.Blank)
+			if err != nil {
+				return nil, nil, err
+			}
+			structMap[structKey][fieldKey] = field
+		}
+	}
+	BuildIndexes(structMap)
+	return structMap, payload.ValidationReport, nil
+}
+
+func (p *snapshotPayload) record(structKey string, position int) (interface{}, error) {
+	records := p.Records[structKey]
+	if position < len(records) {
+		return records[position], nil
+	}
+	return nil, fmt.Errorf("index snapshot refers to a missing record %d of struct map key %s", position, structKey)
+}
+
+//records returns the records at the positions, or nil when there is none
+func (p *snapshotPayload) records(structKey string, positions []int) (records []interface{}, err error) {
+	for _, position := range positions {
+		record, err := p.record(structKey, position)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return
+}
+
+//positionsOf returns the positions of the records in the snapshot payload
+func positionsOf(records []interface{}, positions map[interface{}]int) []int {
+	recordPositions := make([]int, len(records))
+	for i, record := range records {
+		recordPositions[i] = positions[record]
+	}
+	return recordPositions
+}
+
+//SameFingerprints returns true when both lists describe the same versions of the same data files
+func SameFingerprints(a, b []SourceFingerprint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//collectRecords returns every record indexed in the field map, each record only once
+func collectRecords(fieldMap map[string]Field) []interface{} {
+	records := []interface{}{}
+	isCollected := map[interface{}]bool{}
+	for _, field := range fieldMap {
+		lists := [][]interface{}{field.Missing, field.Blank}
+		for _, list := range field.ValueMap {
+			lists = append(lists, list)
+		}
+		for _, list := range lists {
+			for _, record := range list {
+				if !isCollected[record] {
+					isCollected[record] = true
+					records = append(records, record)
+				}
+			}
+		}
+	}
+	return records
+}
@@ -0,0 +1,112 @@
+package data_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/config"
+	"searchDemo/src/data"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"tickets":       `[{"_id": "t1", "status": "pending", "submitter_id": 1, "tags": ["Ohio"]}, {"_id": "t2", "status": "pending", "submitter_id": 2}]`,
+		"users":         `[{"_id": 1, "name": "Francisca"}, {"_id": 2, "name": "Cross"}]`,
+		"organizations": `[{"_id": 101, "name": "Enthaze"}]`,
+	}
+	for label, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, label+".json"), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshotFile := filepath.Join(dir, "index.snapshot")
+	dataService := data.NewService(data.NewStreamSerializer(), config.Config{DataDir: dir, SnapshotFile: snapshotFile})
+
+	_, _, err = dataService.LoadSnapshot()
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected loading a missing snapshot returns os.ErrNotExist, but actual error is <%v>", err)
+	}
+
+	dataset, err := dataService.LoadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	structMap, err := dataService.PrepareStructMap(dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &data.ValidationReport{Records: map[string]int{"tickets": 2}}
+	err = dataService.SaveSnapshot(structMap, report)
+	if err != nil {
+		t.Fatalf("Expected the snapshot is saved, but actual error is <%v>", err)
+	}
+
+	loadedStructMap, loadedReport, err := dataService.LoadSnapshot()
+	if err != nil {
+		t.Fatalf("Expected the snapshot is loaded, but actual error is <%v>", err)
+	}
+	if loadedReport == nil || loadedReport.Records["tickets"] != 2 {
+		t.Errorf("Expected the validation report is restored from the snapshot, but actual report is <%+v>", loadedReport)
+	}
+	pending := loadedStructMap["tickets"]["status"].ValueMap["pending"]
+	if len(pending) != 2 || pending[0].(*data.Ticket).ID != "t1" || pending[1].(*data.Ticket).ID != "t2" {
+		t.Errorf("Expected 2 pending tickets in the restored struct map, but actual records are <%v>", pending)
+	}
+	//The same record must be shared by every field of the restored struct map, as it is in the built one
+	if loadedStructMap["tickets"]["tags"].ValueMap["ohio"][0] != pending[0] {
+		t.Errorf("Expected the restored fields share the same record pointers, but actually not")
+	}
+	//The missing fields of the records are not saved with them, so they are restored from the missing records of each field
+	if !data.IsMissing(pending[1], "Tags") || data.IsMissing(pending[0], "Tags") {
+		t.Errorf("Expected the restored tickets remember the missing tags, but actually not")
+	}
+	if len(loadedStructMap["users"]["name"].ValueMap) != 2 || len(loadedStructMap["organizations"]["id"].ValueMap["101"]) != 1 {
+		t.Errorf("Expected the users and organizations are restored from the snapshot, but actually not")
+	}
+
+	//A snapshot saved in another validation mode is stale, so a strict run validates the data again
+	strictDataService := data.NewService(data.NewStreamSerializer(), config.Config{DataDir: dir, SnapshotFile: snapshotFile, ValidationMode: data.ValidationStrict})
+	_, _, err = strictDataService.LoadSnapshot()
+	if !errors.Is(err, data.ErrSnapshotStale) {
+		t.Errorf("Expected loading a snapshot saved in another validation mode returns ErrSnapshotStale, but actual error is <%v>", err)
+	}
+
+	//A changed data file makes the snapshot stale
+	future := time.Now().Add(time.Hour)
+	err = os.Chtimes(filepath.Join(dir, "users.json"), future, future)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = dataService.LoadSnapshot()
+	if !errors.Is(err, data.ErrSnapshotStale) {
+		t.Errorf("Expected loading the snapshot after a data file changed returns ErrSnapshotStale, but actual error is <%v>", err)
+	}
+
+	//A corrupted snapshot fails the checksum
+	err = dataService.SaveSnapshot(structMap, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(snapshotFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content[len(content)-1] ^= 0xff
+	err = ioutil.WriteFile(snapshotFile, content, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = dataService.LoadSnapshot()
+	if err == nil {
+		t.Errorf("Expected loading a corrupted snapshot returns an error, but actually not")
+	}
+}
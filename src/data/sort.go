@@ -0,0 +1,117 @@
+package data
+
+import (
+	"strings"
+	"time"
+)
+
+//HasValue returns true when the record has a value for this field, ie. it is neither missing from the data file nor an empty string or list
+func (f Field) HasValue(record interface{}) bool {
+	return !f.IsMissing(record) && !isBlank(f.Value(record))
+}
+
+//SortKey is the value of a record for a field, parsed once to be compared with the values of the other records by CompareKeys
+type SortKey struct {
+	integer  int
+	float    float64
+	boolean  bool
+	time     time.Time
+	isTime   bool
+	text     string
+	position int
+}
+
+//SortKey returns the value of the record for this field, parsed as the type of the field: a number, a boolean or an instant,
+//and the lower case text with its position in the Order of the field
+func (f Field) SortKey(record interface{}) (key SortKey) {
+	value := f.Value(record)
+	switch f.Type {
+	case "int":
+		key.integer, _ = value.(int)
+	case "float64":
+		key.float, _ = value.(float64)
+	case "bool":
+		key.boolean, _ = value.(bool)
+	case "time":
+		t, err := ParseTimestamp(sortText(value))
+		key.time, key.isTime = t, err == nil
+	}
+	key.text = strings.ToLower(sortText(value))
+	if len(f.Order) != 0 {
+		key.position = orderPosition(f.Order, key.text)
+	}
+	return
+}
+
+//CompareKeys orders two sort keys of this field, -1 when a comes first: numbers by value, timestamps chronologically, booleans false first,
+//the values of a field with an Order by their position in it, followed by the values it does not list, and the other values alphabetically, case insensitively
+func (f Field) CompareKeys(a, b SortKey) int {
+	switch f.Type {
+	case "int":
+		return compareInts(a.integer, b.integer)
+	case "float64":
+		return compareFloats(a.float, b.float)
+	case "bool":
+		if a.boolean == b.boolean {
+			return 0
+		}
+		if !a.boolean {
+			return -1
+		}
+		return 1
+	case "time":
+		if a.isTime && b.isTime {
+			return a.time.Compare(b.time)
+		}
+	}
+	if len(f.Order) != 0 && a.position != b.position {
+		return compareInts(a.position, b.position)
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+//Compare orders two records by their value of this field like CompareKeys; a sort of many records computes their sort keys once instead
+func (f Field) Compare(a, b interface{}) int {
+	return f.CompareKeys(f.SortKey(a), f.SortKey(b))
+}
+
+//sortText returns the text a value is sorted by; a list is sorted by its values joined in their order
+func sortText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return ""
+}
+
+//orderPosition returns the position of the lower case value in the order, or the length of the order for a value it does not list
+func orderPosition(order []string, value string) int {
+	for i, ordered := range order {
+		if strings.ToLower(ordered) == value {
+			return i
+		}
+	}
+	return len(order)
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func compareInts(x, y int) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package data_test
+
+import (
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestFieldCompare(t *testing.T) {
+	testCases := map[string]struct {
+		field           data.Field
+		a               *data.Ticket
+		b               *data.Ticket
+		expectedCompare int
+	}{
+		"ints are compared as numbers": {
+			field:           data.Field{Type: "int", NameWithCase: "SubmitterID"},
+			a:               &data.Ticket{SubmitterID: 9},
+			b:               &data.Ticket{SubmitterID: 10},
+			expectedCompare: -1,
+		},
+		"timestamps are compared as instants": {
+			field:           data.Field{Type: "time", NameWithCase: "CreatedAt"},
+			a:               &data.Ticket{CreatedAt: "2016-04-28T11:19:34 -10:00"},
+			b:               &data.Ticket{CreatedAt: "2016-04-28T20:19:34Z"},
+			expectedCompare: 1,
+		},
+		"the same instant in another time zone": {
+			field:           data.Field{Type: "time", NameWithCase: "CreatedAt"},
+			a:               &data.Ticket{CreatedAt: "2016-04-28T11:19:34 -10:00"},
+			b:               &data.Ticket{CreatedAt: "2016-04-28T21:19:34Z"},
+			expectedCompare: 0,
+		},
+		"values are compared by their order": {
+			field:           data.Field{Type: "string", NameWithCase: "Priority", Order: []string{"low", "normal", "high", "urgent"}},
+			a:               &data.Ticket{Priority: "Urgent"},
+			b:               &data.Ticket{Priority: "normal"},
+			expectedCompare: 1,
+		},
+		"values the order does not list come last": {
+			field:           data.Field{Type: "string", NameWithCase: "Priority", Order: []string{"low", "normal", "high", "urgent"}},
+			a:               &data.Ticket{Priority: "critical"},
+			b:               &data.Ticket{Priority: "urgent"},
+			expectedCompare: 1,
+		},
+		"strings are compared alphabetically, case insensitively": {
+			field:           data.Field{Type: "string", NameWithCase: "Status"},
+			a:               &data.Ticket{Status: "Closed"},
+			b:               &data.Ticket{Status: "hold"},
+			expectedCompare: -1,
+		},
+	}
+	for tc, tp := range testCases {
+		compare := tp.field.Compare(tp.a, tp.b)
+		if compare != tp.expectedCompare {
+			t.Errorf("For test case <%s>, Expected compare is <%d>, but actual compare is <%d>", tc, tp.expectedCompare, compare)
+		}
+	}
+}
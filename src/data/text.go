@@ -0,0 +1,206 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//stopWords are left out of the full-text index and the search values, as nearly every text contains them
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true, "by": true,
+	"for": true, "from": true, "has": true, "have": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "no": true, "not": true, "of": true, "on": true, "or": true, "so": true, "such": true, "that": true,
+	"the": true, "their": true, "then": true, "there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "were": true, "will": true, "with": true,
+}
+
+//foldedRunes replaces the accented latin letters by their base letters, so "café" and "cafe" are the same token
+var foldedRunes = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ð': "d",
+}
+
+//Tokenize splits a text into its full-text tokens: the text is lower cased, accents are removed, it is split on every character which is not a letter or a digit,
+//and the stop words are dropped. Ex. "A Catastrophe in Korea (North)" has the tokens catastrophe, korea and north.
+func Tokenize(text string) (tokens []string) {
+	builder := strings.Builder{}
+	flush := func() {
+		token := builder.String()
+		builder.Reset()
+		if len(token) != 0 && !stopWords[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			//Combining marks of decomposed text, ex. the accent of "é"
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			folded, ok := foldedRunes[r]
+			if ok {
+				builder.WriteString(folded)
+			} else {
+				builder.WriteRune(r)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return
+}
+
+//The BM25 parameters: k1 limits how much a word repeated in a text adds to its score, b how much a long text is scored below a short one
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+//textIndex is the inverted index of a full-text field: each token lists the records whose value contains it, in the order of the value map.
+//It keeps the word counts of every record and the average text length to score the matches with BM25.
+type textIndex struct {
+	postings      map[string][]interface{}
+	terms         []string
+	fuzzy         fuzzyIndex
+	valueMap      map[string][]interface{}
+	termCounts    map[interface{}]map[string]int
+	lengths       map[interface{}]int
+	averageLength float64
+}
+
+func newTextIndex(field Field) textIndex {
+	index := textIndex{
+		postings:   map[string][]interface{}{},
+		valueMap:   field.ValueMap,
+		termCounts: map[interface{}]map[string]int{},
+		lengths:    map[interface{}]int{},
+	}
+	totalLength := 0
+	for _, value := range sortedKeys(field.ValueMap) {
+		tokens := Tokenize(value)
+		for _, record := range field.ValueMap[value] {
+			if _, ok := index.lengths[record]; ok {
+				continue
+			}
+			counts := map[string]int{}
+			for _, token := range tokens {
+				if counts[token] == 0 {
+					index.postings[token] = append(index.postings[token], record)
+				}
+				counts[token]++
+			}
+			index.termCounts[record] = counts
+			index.lengths[record] = len(tokens)
+			totalLength += len(tokens)
+		}
+	}
+	index.terms = sortedKeys(index.postings)
+	index.fuzzy = newFuzzyIndex(index.terms)
+	if len(index.lengths) != 0 {
+		index.averageLength = float64(totalLength) / float64(len(index.lengths))
+	}
+	return index
+}
+
+//sortedKeys returns the values of a value map in ascending order, so the postings do not depend on the map order
+func sortedKeys(valueMap map[string][]interface{}) []string {
+	keys := make([]string, 0, len(valueMap))
+	for key := range valueMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//Lookup returns the records containing every token of the search value. A value without any token, ex. "" or "the", is matched against the whole value instead.
+func (i textIndex) Lookup(value string) ([]interface{}, error) {
+	tokens := Tokenize(value)
+	if len(tokens) == 0 {
+		return i.valueMap[strings.ToLower(value)], nil
+	}
+	records := i.postings[tokens[0]]
+	for _, token := range tokens[1:] {
+		isPosted := map[interface{}]bool{}
+		for _, record := range i.postings[token] {
+			isPosted[record] = true
+		}
+		matched := []interface{}{}
+		for _, record := range records {
+			if isPosted[record] {
+				matched = append(matched, record)
+			}
+		}
+		records = matched
+	}
+	return records, nil
+}
+
+//Match returns the records with a word matching the pattern, ex. fran* matches the user Francisca Rasmussen
+func (i textIndex) Match(pattern Pattern) ([]interface{}, error) {
+	return matchTerms(i.terms, i.postings, pattern)
+}
+
+//Fuzzy returns the records containing a word close to every word of the search value, ex. fransisca rasmusen matches Francisca Rasmussen
+func (i textIndex) Fuzzy(value string, maxDistance int) ([]interface{}, error) {
+	tokens := Tokenize(value)
+	if len(tokens) == 0 {
+		return i.Lookup(value)
+	}
+	records := i.fuzzy.records(i.postings, tokens[0], maxDistance)
+	for _, token := range tokens[1:] {
+		isMatched := map[interface{}]bool{}
+		for _, record := range i.fuzzy.records(i.postings, token, maxDistance) {
+			isMatched[record] = true
+		}
+		matched := []interface{}{}
+		for _, record := range records {
+			if isMatched[record] {
+				matched = append(matched, record)
+			}
+		}
+		records = matched
+	}
+	return records, nil
+}
+
+//Suggest returns the indexed words close to the words of the search value which are not indexed
+func (i textIndex) Suggest(value string, maxDistance int) (suggestions []Suggestion) {
+	for _, token := range Tokenize(value) {
+		if len(i.postings[token]) != 0 {
+			continue
+		}
+		suggestions = append(suggestions, i.fuzzy.closest(token, maxDistance)...)
+	}
+	return
+}
+
+//Score returns the BM25 score of the record for the words of the search value: a word scores higher when it is repeated in the text,
+//when the text is short and when few other records contain it. Records without any of the words score 0.
+func (i textIndex) Score(value string, record interface{}) (score float64) {
+	counts, ok := i.termCounts[record]
+	if !ok || i.averageLength == 0 {
+		return 0
+	}
+	total := float64(len(i.lengths))
+	lengthNorm := 1 - bm25B + bm25B*float64(i.lengths[record])/i.averageLength
+	for _, token := range Tokenize(value) {
+		count := float64(counts[token])
+		if count == 0 {
+			continue
+		}
+		matched := float64(len(i.postings[token]))
+		idf := math.Log(1 + (total-matched+0.5)/(matched+0.5))
+		score += idf * count * (bm25K1 + 1) / (count + bm25K1*lengthNorm)
+	}
+	return
+}
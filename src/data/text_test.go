@@ -0,0 +1,84 @@
+package data_test
+
+import (
+	"math"
+	"reflect"
+	"searchDemo/src/data"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := map[string]struct {
+		text           string
+		expectedTokens []string
+	}{
+		"punctuation splits the words": {
+			text:           "A Catastrophe in Korea (North)",
+			expectedTokens: []string{"catastrophe", "korea", "north"},
+		},
+		"accents are removed": {
+			text:           "Café Müller, São Paulo",
+			expectedTokens: []string{"cafe", "muller", "sao", "paulo"},
+		},
+		"combining marks are removed": {
+			text:           "Cafe\u0301",
+			expectedTokens: []string{"cafe"},
+		},
+		"digits are kept in the words": {
+			text:           "Problem #1 in user2@test.com",
+			expectedTokens: []string{"problem", "1", "user2", "test", "com"},
+		},
+		"only stop words": {
+			text: "The and of",
+		},
+	}
+	for tc, tp := range testCases {
+		tokens := data.Tokenize(tp.text)
+		if !reflect.DeepEqual(tokens, tp.expectedTokens) {
+			t.Errorf("For test case <%s>, Expected tokens are <%v>, but actual tokens are <%v>", tc, tp.expectedTokens, tokens)
+		}
+	}
+}
+
+func TestFieldScore(t *testing.T) {
+	short, long, repeated, other := "short", "long", "repeated", "other"
+	field := data.Field{Type: "text", ValueMap: map[string][]interface{}{
+		"catastrophe in korea":                                 []interface{}{short},
+		"a catastrophe reported by our office in seoul, korea": []interface{}{long},
+		"korea korea korea":                                    []interface{}{repeated},
+		"nothing to report":                                    []interface{}{other},
+	}}
+	testCases := map[string]struct {
+		value         string
+		expectedOrder []string
+	}{
+		"a shorter text scores higher": {
+			value:         "catastrophe",
+			expectedOrder: []string{short, long},
+		},
+		"a repeated word scores higher": {
+			value:         "korea",
+			expectedOrder: []string{repeated, short, long},
+		},
+		"a record matching more words scores higher": {
+			value:         "korea seoul",
+			expectedOrder: []string{long, short},
+		},
+	}
+	for tc, tp := range testCases {
+		for i := 1; i < len(tp.expectedOrder); i++ {
+			higher, lower := field.Score(tp.value, tp.expectedOrder[i-1]), field.Score(tp.value, tp.expectedOrder[i])
+			if higher <= lower {
+				t.Errorf("For test case <%s>, Expected %s scores above %s, but actual scores are <%f> and <%f>", tc, tp.expectedOrder[i-1], tp.expectedOrder[i], higher, lower)
+			}
+		}
+	}
+	if score := field.Score("seoul", other); score != 0 {
+		t.Errorf("Expected a record without the word scores 0, but actual score is <%f>", score)
+	}
+	boosted := field
+	boosted.Boost = 3
+	if math.Abs(boosted.Score("korea", short)-3*field.Score("korea", short)) > 1e-9 {
+		t.Errorf("Expected the boost multiplies the score, but actual scores are <%f> and <%f>", boosted.Score("korea", short), field.Score("korea", short))
+	}
+}
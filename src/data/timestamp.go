@@ -0,0 +1,32 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//TimestampLayout is the format of created_at, due_at and last_login_at in the data files, ex. 2016-04-28T11:19:34 -10:00
+const TimestampLayout = "2006-01-02T15:04:05 -07:00"
+
+//timestampLayouts are tried in order by ParseTimestamp; the layouts without a zone are read as UTC
+var timestampLayouts = []string{
+	TimestampLayout,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+//ParseTimestamp parses a timestamp in the format of the data files, also accepting RFC 3339 and plain dates. It is case insensitive, as the indexed values are lower case.
+func ParseTimestamp(value string) (t time.Time, err error) {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	for _, layout := range timestampLayouts {
+		t, err = time.Parse(layout, normalized)
+		if err == nil {
+			return
+		}
+	}
+	err = fmt.Errorf("%q is not a valid timestamp, expected the format %s", value, TimestampLayout)
+	return
+}
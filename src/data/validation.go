@@ -0,0 +1,213 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	//ValidationStrict aborts the startup when the data has any validation failure
+	ValidationStrict = "strict"
+	//ValidationLenient reports the validation failures and loads the data anyway
+	ValidationLenient = "lenient"
+	//ValidationOff skips the validation
+	ValidationOff = "off"
+)
+
+var (
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,}[0-9]$`)
+
+	ticketTypes      = []string{"incident", "problem", "question", "task"}
+	ticketPriorities = []string{"low", "normal", "high", "urgent"}
+	ticketStatuses   = []string{"new", "open", "pending", "hold", "solved", "closed"}
+	ticketChannels   = []string{"web", "chat", "voice", "email", "api"}
+	userRoles        = []string{"end-user", "agent", "admin"}
+)
+
+//ValidationReport summarises the data quality problems found after loading. Records counts the loaded records of each entity,
+//and Failures lists every broken rule with the IDs of the offending records.
+type ValidationReport struct {
+	Records  map[string]int `json:"records"`
+	Failures []RuleFailure  `json:"failures"`
+}
+
+//RuleFailure is a validation rule (ex. enum) broken by one or more records on the same entity field
+type RuleFailure struct {
+	Entity    string   `json:"entity"`
+	Field     string   `json:"field"`
+	Rule      string   `json:"rule"`
+	Message   string   `json:"message"`
+	Count     int      `json:"count"`
+	RecordIDs []string `json:"record_ids"`
+}
+
+//HasFailures returns true when any record breaks a validation rule
+func (r *ValidationReport) HasFailures() bool {
+	return r != nil && len(r.Failures) != 0
+}
+
+//Text returns the human readable report
+func (r *ValidationReport) Text() string {
+	lines := []string{"Data validation report", "======================"}
+	for _, entity := range Entities() {
+		lines = append(lines, fmt.Sprintf("%s: %d records", entity.Name, r.Records[entity.Name]))
+	}
+	if len(r.Failures) == 0 {
+		lines = append(lines, "No validation failures found")
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, fmt.Sprintf("%d validation failures:", len(r.Failures)))
+	for _, failure := range r.Failures {
+		lines = append(lines, fmt.Sprintf("  %s.%s (%s) %s: %d records: %s", failure.Entity, failure.Field, failure.Rule, failure.Message, failure.Count, strings.Join(failure.RecordIDs, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+//Validate func checks the loaded records for missing required fields, duplicated or malformed IDs, unparsable timestamps,
+//unknown enum values and malformed emails and phone numbers
+func Validate(tickets []*Ticket, users []*User, organizations []*Organization) *ValidationReport {
+	v := &validator{failures: map[string]*RuleFailure{}}
+
+	ticketIDs := map[string]bool{}
+	for i, ticket := range tickets {
+		id := recordID(ticket.ID, i)
+		v.required("tickets", id, "_id", ticket.ID)
+		v.required("tickets", id, "created_at", ticket.CreatedAt)
+		v.required("tickets", id, "subject", ticket.Subject)
+		v.required("tickets", id, "status", ticket.Status)
+		v.required("tickets", id, "priority", ticket.Priority)
+		v.requiredInt("tickets", id, "submitter_id", ticket.SubmitterID)
+		if len(ticket.ID) != 0 {
+			v.check(uuidPattern.MatchString(ticket.ID), "tickets", id, "_id", "format", "is not a UUID")
+			v.check(!ticketIDs[ticket.ID], "tickets", id, "_id", "unique", "is duplicated")
+			ticketIDs[ticket.ID] = true
+		}
+		v.timestamp("tickets", id, "created_at", ticket.CreatedAt)
+		v.timestamp("tickets", id, "due_at", ticket.DueAt)
+		v.enum("tickets", id, "type", ticket.Type, ticketTypes)
+		v.enum("tickets", id, "priority", ticket.Priority, ticketPriorities)
+		v.enum("tickets", id, "status", ticket.Status, ticketStatuses)
+		v.enum("tickets", id, "via", ticket.Via, ticketChannels)
+	}
+
+	userIDs := map[int]bool{}
+	for i, user := range users {
+		id := recordID(strconv.Itoa(user.ID), i)
+		v.requiredInt("users", id, "_id", user.ID)
+		v.required("users", id, "name", user.Name)
+		v.required("users", id, "created_at", user.CreatedAt)
+		v.required("users", id, "role", user.Role)
+		if user.ID != 0 {
+			v.check(!userIDs[user.ID], "users", id, "_id", "unique", "is duplicated")
+			userIDs[user.ID] = true
+		}
+		v.timestamp("users", id, "created_at", user.CreatedAt)
+		v.timestamp("users", id, "last_login_at", user.LastLoginAt)
+		v.enum("users", id, "role", user.Role, userRoles)
+		v.pattern("users", id, "email", user.Email, emailPattern, "is not a valid email address")
+		v.pattern("users", id, "phone", user.Phone, phonePattern, "is not a valid phone number")
+	}
+
+	organizationIDs := map[int]bool{}
+	for i, organization := range organizations {
+		id := recordID(strconv.Itoa(organization.ID), i)
+		v.requiredInt("organizations", id, "_id", organization.ID)
+		v.required("organizations", id, "name", organization.Name)
+		v.required("organizations", id, "created_at", organization.CreatedAt)
+		if organization.ID != 0 {
+			v.check(!organizationIDs[organization.ID], "organizations", id, "_id", "unique", "is duplicated")
+			organizationIDs[organization.ID] = true
+		}
+		v.timestamp("organizations", id, "created_at", organization.CreatedAt)
+	}
+
+	return v.report(map[string]int{"tickets": len(tickets), "users": len(users), "organizations": len(organizations)})
+}
+
+//recordID identifies a record in the report; records without an ID are identified by their index in the data file
+func recordID(id string, index int) string {
+	if len(id) == 0 || id == "0" {
+		return fmt.Sprintf("#%d", index)
+	}
+	return id
+}
+
+type validator struct {
+	failures map[string]*RuleFailure
+}
+
+func (v *validator) check(isValid bool, entity, recordID, field, rule, message string) {
+	if isValid {
+		return
+	}
+	key := strings.Join([]string{entity, field, rule}, "\x00")
+	failure, ok := v.failures[key]
+	if !ok {
+		failure = &RuleFailure{Entity: entity, Field: field, Rule: rule, Message: message, RecordIDs: []string{}}
+		v.failures[key] = failure
+	}
+	failure.Count++
+	failure.RecordIDs = append(failure.RecordIDs, recordID)
+}
+
+func (v *validator) required(entity, recordID, field, value string) {
+	v.check(len(strings.TrimSpace(value)) != 0, entity, recordID, field, "required", "is missing")
+}
+
+func (v *validator) requiredInt(entity, recordID, field string, value int) {
+	v.check(value != 0, entity, recordID, field, "required", "is missing")
+}
+
+//timestamp only checks the values which are set, missing values are reported by the required rule
+func (v *validator) timestamp(entity, recordID, field, value string) {
+	if len(value) == 0 {
+		return
+	}
+	_, err := ParseTimestamp(value)
+	v.check(err == nil, entity, recordID, field, "timestamp", "is not a valid timestamp")
+}
+
+func (v *validator) enum(entity, recordID, field, value string, allowed []string) {
+	if len(value) == 0 {
+		return
+	}
+	isAllowed := false
+	for _, a := range allowed {
+		if value == a {
+			isAllowed = true
+			break
+		}
+	}
+	v.check(isAllowed, entity, recordID, field, "enum", "is not one of "+strings.Join(allowed, ", "))
+}
+
+func (v *validator) pattern(entity, recordID, field, value string, pattern *regexp.Regexp, message string) {
+	if len(value) == 0 {
+		return
+	}
+	v.check(pattern.MatchString(value), entity, recordID, field, "format", message)
+}
+
+func (v *validator) report(records map[string]int) *ValidationReport {
+	order := map[string]int{"tickets": 0, "users": 1, "organizations": 2}
+	report := &ValidationReport{Records: records, Failures: []RuleFailure{}}
+	for _, failure := range v.failures {
+		report.Failures = append(report.Failures, *failure)
+	}
+	sort.Slice(report.Failures, func(i, j int) bool {
+		a, b := report.Failures[i], report.Failures[j]
+		if a.Entity != b.Entity {
+			return order[a.Entity] < order[b.Entity]
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Rule < b.Rule
+	})
+	return report
+}
@@ -0,0 +1,84 @@
+package data_test
+
+import (
+	"encoding/json"
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	validTicket := *mock.MockTickets[0]
+	validTicket.ID = "436bf9b0-1147-4c0a-8439-6f79833bff5b"
+	validTicket.CreatedAt = "2016-04-28T11:19:34 -10:00"
+	validTicket.DueAt = ""
+
+	duplicatedTicket := validTicket
+	duplicatedTicket.Type = "outage"
+	duplicatedTicket.Via = "fax"
+
+	brokenTicket := validTicket
+	brokenTicket.ID = "t3"
+	brokenTicket.Subject = ""
+	brokenTicket.CreatedAt = "yesterday"
+
+	validUser := *mock.MockUsers[0]
+	validUser.Phone = "8335-422-718"
+	brokenUser := validUser
+	brokenUser.Role = "owner"
+	brokenUser.Email = "coffeyrasmussen.flotonic.com"
+	brokenUser.Phone = "call me"
+	brokenUser.LastLoginAt = "2013-13-04T01:03:27 -10:00"
+
+	organization := *mock.MockOrganizations[0]
+
+	testCases := map[string]struct {
+		tickets          []*data.Ticket
+		users            []*data.User
+		organizations    []*data.Organization
+		expectedFailures []string
+	}{
+		"valid records have no failures": {
+			tickets:          []*data.Ticket{&validTicket},
+			users:            []*data.User{&validUser},
+			organizations:    []*data.Organization{&organization},
+			expectedFailures: []string{},
+		},
+		"every broken rule is reported with the offending record IDs": {
+			tickets:       []*data.Ticket{&validTicket, &duplicatedTicket, &brokenTicket},
+			users:         []*data.User{&validUser, &brokenUser},
+			organizations: []*data.Organization{&organization, &organization},
+			expectedFailures: []string{
+				"tickets._id format t3",
+				"tickets._id unique 436bf9b0-1147-4c0a-8439-6f79833bff5b",
+				"tickets.created_at timestamp t3",
+				"tickets.subject required t3",
+				"tickets.type enum 436bf9b0-1147-4c0a-8439-6f79833bff5b",
+				"tickets.via enum 436bf9b0-1147-4c0a-8439-6f79833bff5b",
+				"users._id unique 1",
+				"users.email format 1",
+				"users.last_login_at timestamp 1",
+				"users.phone format 1",
+				"users.role enum 1",
+				"organizations._id unique 1",
+			},
+		},
+	}
+	for tc, tp := range testCases {
+		report := data.Validate(tp.tickets, tp.users, tp.organizations)
+		actualFailures := []string{}
+		for _, failure := range report.Failures {
+			actualFailures = append(actualFailures, failure.Entity+"."+failure.Field+" "+failure.Rule+" "+strings.Join(failure.RecordIDs, ","))
+		}
+		if strings.Join(actualFailures, "\n") != strings.Join(tp.expectedFailures, "\n") {
+			t.Errorf("For test case <%s>, Expected failures are <%v>, but actual failures are <%v>", tc, tp.expectedFailures, actualFailures)
+		}
+		if report.Records["tickets"] != len(tp.tickets) {
+			t.Errorf("For test case <%s>, Expected the report counts <%d> tickets, but actual count is <%d>", tc, len(tp.tickets), report.Records["tickets"])
+		}
+		if _, err := json.Marshal(report); err != nil {
+			t.Errorf("For test case <%s>, Expected the report can be marshaled to JSON, but actual error is <%v>", tc, err)
+		}
+	}
+}
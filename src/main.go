@@ -3,19 +3,55 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"searchDemo/src/config"
 	"searchDemo/src/data"
 	"searchDemo/src/interaction"
+	"searchDemo/src/query"
 	"searchDemo/src/search"
 )
 
 func main() {
-	dataService := data.NewService(data.NewSerializer())
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 && args[0] == "check-refs" {
+		command, args = args[0], args[1:]
+	}
+	//The schema entities add their own file flags, ex. -groups, so the schema is registered before the flags are parsed
+	schemaFile, err := config.LookupSchemaFile(args, os.LookupEnv)
+	if err == nil && len(schemaFile) != 0 {
+		err = registerSchema(schemaFile)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cfg, err := config.Load(args, os.LookupEnv)
+	if err != nil {
+		//flag package already prints the usage for invalid flags and -h
+		if err != flag.ErrHelp {
+			fmt.Println(err)
+		}
+		return
+	}
+	serializer := data.NewExtensionSerializer(data.NewStreamSerializer(), map[string]data.StreamSerializer{
+		".csv": data.NewCSVSerializer(cfg.CSVListDelimiter),
+	})
+	dataService := data.NewService(serializer, cfg)
+	if command == "check-refs" {
+		os.Exit(checkReferences(dataService, cfg.ReportFormat))
+	}
 	interactionService := interaction.NewService(bufio.NewScanner(os.Stdin))
 	s := search.NewService(dataService, interactionService)
 	//Load the struct map into search service before user gets prompts for searches. If load fails, inform user and exit the application
-	err := s.SetStructMap()
+	err = s.SetStructMap()
+	report := s.GetValidationReport()
+	if report.HasFailures() {
+		printReport(report, cfg.ReportFormat)
+	}
 	if err != nil {
 		fmt.Println(err)
 		fmt.Println("Failed to set the struct map, press any key to exit the application")
@@ -23,16 +59,27 @@ func main() {
 		return
 	}
 
+	data.FuzzyDistance, _ = cfg.MaxFuzzyDistance()
+	search.PageSize, _ = cfg.ResultsPerPage()
+	if len(cfg.Query) != 0 {
+		os.Exit(runQuery(s, cfg.Query))
+	}
+	watchInterval, _ := cfg.WatchDuration()
+	if watchInterval > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go s.Watch(watchInterval, stop)
+	}
+
 	//Loop the StartSearch func so the application will continue to run (either successful or failed search) unless user select to quit
 	for {
 		results, isQuit, err := s.StartSearch()
 		if isQuit {
 			break
 		}
-		if err != nil {
-			fmt.Println(err)
-		} else {
-			printOutput(results)
+		printResults(results, err)
+		if err == nil && browsePages(s, results) {
+			break
 		}
 
 		isNewSearchRequired := s.RequestNewSearch()
@@ -43,6 +90,35 @@ func main() {
 	}
 }
 
+//printResults prints the error of a search, or its results
+func printResults(results interface{}, err error) {
+	if err != nil {
+		printError(err)
+	} else if results != nil {
+		printOutput(results)
+	}
+}
+
+//browsePages prints the next or the previous page of paged results for as long as the user asks for one; it returns true when the user types quit
+func browsePages(s search.Service, results interface{}) bool {
+	for {
+		page, ok := results.(search.Page)
+		if !ok {
+			return false
+		}
+		cursor, isQuit := s.RequestPage(page)
+		if isQuit || len(cursor) == 0 {
+			return isQuit
+		}
+		var err error
+		results, err = s.Page(cursor)
+		printResults(results, err)
+		if err != nil {
+			return false
+		}
+	}
+}
+
 func printOutput(v interface{}) {
 	resultsBytes, err := json.Marshal(v)
 	if err != nil {
@@ -51,3 +127,55 @@ func printOutput(v interface{}) {
 	resultsJSONString := string(resultsBytes)
 	fmt.Println(resultsJSONString)
 }
+
+//printError prints the error, and the query with a ^ under the offending column for a query error
+func printError(err error) {
+	fmt.Println(err)
+	var parseErr *query.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println(parseErr.Pointer())
+	}
+}
+
+//runQuery runs the query given by the -query flag and prints the results; it returns the exit status, 1 when the query fails or finds nothing
+func runQuery(s search.Service, q string) int {
+	results, err := s.Query(q)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	printOutput(results)
+	return 0
+}
+
+//registerSchema registers the entities defined in the schema file, so they are loaded and searched along with the built-in ones
+func registerSchema(filePath string) error {
+	schema, err := data.LoadSchema(filePath)
+	if err != nil {
+		return err
+	}
+	return data.RegisterSchema(schema)
+}
+
+//checkReferences loads the data files and prints the reference report; it returns the exit status, 1 when the data has dangling references
+func checkReferences(dataService data.Service, format string) int {
+	dataset, err := dataService.LoadFile()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	report := data.CheckReferences(dataset.Tickets(), dataset.Users(), dataset.Organizations())
+	printReport(report, format)
+	if report.HasDanglingReferences() {
+		return 1
+	}
+	return 0
+}
+
+func printReport(report interface{ Text() string }, format string) {
+	if format == "json" {
+		printOutput(report)
+		return
+	}
+	fmt.Println(report.Text())
+}
@@ -4,7 +4,7 @@ import "searchDemo/src/data"
 
 var (
 	MockStructMap = map[string]map[string]data.Field{
-		"1": map[string]data.Field{
+		"tickets": map[string]data.Field{
 			"id": data.Field{Type: "string", NameWithCase: "ID", ValueMap: map[string][]interface{}{
 				"t1": []interface{}{MockTickets[0]},
 				"t2": []interface{}{MockTickets[1]},
@@ -17,24 +17,24 @@ var (
 				"et1": []interface{}{MockTickets[0]},
 				"et2": []interface{}{MockTickets[1]},
 			}},
-			"createdat": data.Field{Type: "string", NameWithCase: "CreatedAt", ValueMap: map[string][]interface{}{
+			"createdat": data.Field{Type: "time", NameWithCase: "CreatedAt", ValueMap: map[string][]interface{}{
 				"2019-05-11t11:00:01": []interface{}{MockTickets[0]},
 				"2019-05-11t11:00:02": []interface{}{MockTickets[1]},
 			}},
 			"type": data.Field{Type: "string", NameWithCase: "Type", ValueMap: map[string][]interface{}{
 				"incident": []interface{}{MockTickets[0], MockTickets[1]},
 			}},
-			"subject": data.Field{Type: "string", NameWithCase: "Subject", ValueMap: map[string][]interface{}{
+			"subject": data.Field{Type: "text", Boost: 3, NameWithCase: "Subject", ValueMap: map[string][]interface{}{
 				"test1": []interface{}{MockTickets[0]},
 				"test2": []interface{}{MockTickets[1]},
 			}},
-			"description": data.Field{Type: "string", NameWithCase: "Description", ValueMap: map[string][]interface{}{
+			"description": data.Field{Type: "text", NameWithCase: "Description", ValueMap: map[string][]interface{}{
 				"test description": []interface{}{MockTickets[0]},
 				"":                 []interface{}{MockTickets[1]},
-			}},
+			}, Blank: []interface{}{MockTickets[1]}},
 			"priority": data.Field{Type: "string", NameWithCase: "Priority", ValueMap: map[string][]interface{}{
 				"high": []interface{}{MockTickets[0], MockTickets[1]},
-			}},
+			}, Order: []string{"low", "normal", "high", "urgent"}},
 			"status": data.Field{Type: "string", NameWithCase: "Status", ValueMap: map[string][]interface{}{
 				"pending": []interface{}{MockTickets[0], MockTickets[1]},
 			}},
@@ -58,7 +58,7 @@ var (
 			"hasincidents": data.Field{Type: "bool", NameWithCase: "HasIncidents", ValueMap: map[string][]interface{}{
 				"false": []interface{}{MockTickets[0], MockTickets[1]},
 			}},
-			"dueat": data.Field{Type: "string", NameWithCase: "DueAt", ValueMap: map[string][]interface{}{
+			"dueat": data.Field{Type: "time", NameWithCase: "DueAt", ValueMap: map[string][]interface{}{
 				"2019-05-13t11:00:01": []interface{}{MockTickets[0]},
 				"2019-05-13t11:00:02": []interface{}{MockTickets[1]},
 			}},
@@ -66,7 +66,7 @@ var (
 				"web": []interface{}{MockTickets[0], MockTickets[1]},
 			}},
 		},
-		"2": map[string]data.Field{
+		"users": map[string]data.Field{
 			"id": data.Field{Type: "int", NameWithCase: "ID", ValueMap: map[string][]interface{}{
 				"1": []interface{}{MockUsers[0]},
 				"2": []interface{}{MockUsers[1]},
@@ -79,7 +79,7 @@ var (
 				"u1": []interface{}{MockUsers[0]},
 				"u2": []interface{}{MockUsers[1]},
 			}},
-			"name": data.Field{Type: "string", NameWithCase: "Name", ValueMap: map[string][]interface{}{
+			"name": data.Field{Type: "text", Boost: 3, NameWithCase: "Name", ValueMap: map[string][]interface{}{
 				"test testa": []interface{}{MockUsers[0]},
 				"test testb": []interface{}{MockUsers[1]},
 			}},
@@ -87,7 +87,7 @@ var (
 				"user 1": []interface{}{MockUsers[0]},
 				"user 2": []interface{}{MockUsers[1]},
 			}},
-			"createdat": data.Field{Type: "string", NameWithCase: "CreatedAt", ValueMap: map[string][]interface{}{
+			"createdat": data.Field{Type: "time", NameWithCase: "CreatedAt", ValueMap: map[string][]interface{}{
 				"2019-04-11t11:00:01": []interface{}{MockUsers[0]},
 				"2019-04-11t11:00:02": []interface{}{MockUsers[1]},
 			}},
@@ -106,7 +106,7 @@ var (
 			"timezone": data.Field{Type: "string", NameWithCase: "TimeZone", ValueMap: map[string][]interface{}{
 				"australia": []interface{}{MockUsers[0], MockUsers[1]},
 			}},
-			"lastloginat": data.Field{Type: "string", NameWithCase: "LastLoginAt", ValueMap: map[string][]interface{}{
+			"lastloginat": data.Field{Type: "time", NameWithCase: "LastLoginAt", ValueMap: map[string][]interface{}{
 				"2019-05-12t11:00:01": []interface{}{MockUsers[0]},
 				"2019-05-12t11:00:02": []interface{}{MockUsers[1]},
 			}},
@@ -118,10 +118,10 @@ var (
 				"9991": []interface{}{MockUsers[0]},
 				"9992": []interface{}{MockUsers[1]},
 			}},
-			"signature": data.Field{Type: "string", NameWithCase: "Signature", ValueMap: map[string][]interface{}{
+			"signature": data.Field{Type: "text", NameWithCase: "Signature", ValueMap: map[string][]interface{}{
 				"":                []interface{}{MockUsers[0]},
 				"user signature2": []interface{}{MockUsers[1]},
-			}},
+			}, Blank: []interface{}{MockUsers[0]}},
 			"organizationid": data.Field{Type: "int", NameWithCase: "OrganizationID", ValueMap: map[string][]interface{}{
 				"1": []interface{}{MockUsers[0], MockUsers[1]},
 			}},
@@ -139,7 +139,7 @@ var (
 				"user":  []interface{}{MockUsers[1]},
 			}},
 		},
-		"3": map[string]data.Field{
+		"organizations": map[string]data.Field{
 			"id": data.Field{Type: "int", NameWithCase: "ID", ValueMap: map[string][]interface{}{
 				"1": []interface{}{MockOrganizations[0]},
 			}},
@@ -149,17 +149,17 @@ var (
 			"externalid": data.Field{Type: "string", NameWithCase: "ExternalID", ValueMap: map[string][]interface{}{
 				"o1": []interface{}{MockOrganizations[0]},
 			}},
-			"name": data.Field{Type: "string", NameWithCase: "Name", ValueMap: map[string][]interface{}{
+			"name": data.Field{Type: "text", Boost: 3, NameWithCase: "Name", ValueMap: map[string][]interface{}{
 				"test org1": []interface{}{MockOrganizations[0]},
 			}},
 			"domainnames": data.Field{Type: "[]string", NameWithCase: "DomainNames", ValueMap: map[string][]interface{}{
 				"org1.1.com": []interface{}{MockOrganizations[0]},
 				"org1.2.com": []interface{}{MockOrganizations[0]},
 			}},
-			"createdat": data.Field{Type: "string", NameWithCase: "CreatedAt", ValueMap: map[string][]interface{}{
+			"createdat": data.Field{Type: "time", NameWithCase: "CreatedAt", ValueMap: map[string][]interface{}{
 				"2019-05-01t11:00:00": []interface{}{MockOrganizations[0]},
 			}},
-			"details": data.Field{Type: "string", NameWithCase: "Details", ValueMap: map[string][]interface{}{
+			"details": data.Field{Type: "text", NameWithCase: "Details", ValueMap: map[string][]interface{}{
 				"details1": []interface{}{MockOrganizations[0]},
 			}},
 			"sharedtickets": data.Field{Type: "bool", NameWithCase: "SharedTickets", ValueMap: map[string][]interface{}{
@@ -0,0 +1,122 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Node is a node of a parsed query; String returns the query in its canonical form, with every group in parentheses
+type Node interface {
+	String() string
+}
+
+//Where limits the query to the records of one entity, ex. tickets where status:pending
+type Where struct {
+	Entity string
+	Column int
+	Query  Node
+}
+
+//Term matches the records whose Field has the Value, or any field when Field is empty. Column is where the term starts in the query, to point at it in errors.
+//The Field may follow relations to the field of linked records, ex. organization.tags.
+type Term struct {
+	Field  string
+	Value  string
+	Column int
+}
+
+//And matches the records matched by every operand
+type And struct {
+	Operands []Node
+}
+
+//Or matches the records matched by any operand
+type Or struct {
+	Operands []Node
+}
+
+//Not matches the records which are not matched by the operand
+type Not struct {
+	Operand Node
+}
+
+//Path splits the field into the relations to follow and the field of the last linked records, ex. [organization tags]
+func (t Term) Path() []string {
+	return strings.Split(t.Field, ".")
+}
+
+func (w Where) String() string {
+	return w.Entity + " where " + w.Query.String()
+}
+
+func (t Term) String() string {
+	value := t.Value
+	if len(value) == 0 || strings.ContainsAny(value, ` ()"`) {
+		value = strconv.Quote(value)
+	}
+	if len(t.Field) == 0 {
+		return value
+	}
+	return t.Field + ":" + value
+}
+
+func (a And) String() string {
+	return joinOperands(a.Operands, " AND ")
+}
+
+func (o Or) String() string {
+	return joinOperands(o.Operands, " OR ")
+}
+
+func (n Not) String() string {
+	return "NOT " + n.Operand.String()
+}
+
+func joinOperands(operands []Node, operator string) string {
+	texts := make([]string, len(operands))
+	for i, operand := range operands {
+		texts[i] = operand.String()
+	}
+	return "(" + strings.Join(texts, operator) + ")"
+}
+
+//Terms returns the terms of the query in the order they are written
+func Terms(node Node) (terms []Term) {
+	switch n := node.(type) {
+	case Term:
+		terms = append(terms, n)
+	case And:
+		for _, operand := range n.Operands {
+			terms = append(terms, Terms(operand)...)
+		}
+	case Or:
+		for _, operand := range n.Operands {
+			terms = append(terms, Terms(operand)...)
+		}
+	case Not:
+		terms = append(terms, Terms(n.Operand)...)
+	case Where:
+		terms = append(terms, Terms(n.Query)...)
+	}
+	return
+}
+
+//ParseError is a syntax error of a query, or an error of one of its terms, at the 1-based Column of the query
+type ParseError struct {
+	Query   string
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("query error at column %d: %s", e.Column, e.Message)
+}
+
+//Pointer returns the query with a ^ under the column of the error, ex.
+//
+//	status:pending AND
+//	                  ^
+func (e *ParseError) Pointer() string {
+	return e.Query + "\n" + strings.Repeat(" ", e.Column-1) + "^"
+}
@@ -0,0 +1,125 @@
+package query
+
+import "errors"
+
+//ErrFieldAbsent is returned by the Term of an Evaluator when its records do not have the field of the term, ex. status for the users.
+//A term on an absent field matches no record, and so does its NOT, an AND with it, or an OR of such terms only.
+var ErrFieldAbsent = errors.New("the field of the term is absent")
+
+//Evaluator answers the terms of a query for one set of records, ex. the tickets
+type Evaluator interface {
+	//Term returns the records matching the term
+	Term(term Term) (records []interface{}, err error)
+	//All returns every record in the order of the results; NOT keeps the records which are not matched by its operand
+	All() []interface{}
+}
+
+//Evaluate returns the records matching the query, in the order of All. The terms are looked up once each,
+//then AND intersects, OR unites and NOT subtracts their record sets. The entity of a Where is chosen by the caller, who only evaluates its records.
+func Evaluate(node Node, evaluator Evaluator) (records []interface{}, err error) {
+	matched, _, err := evaluate(node, evaluator)
+	if err != nil {
+		return
+	}
+	for _, record := range evaluator.All() {
+		if matched[record] {
+			records = append(records, record)
+		}
+	}
+	return
+}
+
+type recordSet map[interface{}]bool
+
+//evaluate returns the records matched by the node; isAbsent is true when the node depends on a field the records do not have, so it matches no record even under a NOT
+func evaluate(node Node, evaluator Evaluator) (set recordSet, isAbsent bool, err error) {
+	switch n := node.(type) {
+	case Term:
+		records, err := evaluator.Term(n)
+		if errors.Is(err, ErrFieldAbsent) {
+			return recordSet{}, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		set = recordSet{}
+		for _, record := range records {
+			set[record] = true
+		}
+		return set, false, nil
+	case Or:
+		set, isAbsent = recordSet{}, true
+		for _, operand := range n.Operands {
+			operandSet, isOperandAbsent, err := evaluate(operand, evaluator)
+			if err != nil {
+				return nil, false, err
+			}
+			isAbsent = isAbsent && isOperandAbsent
+			for record := range operandSet {
+				set[record] = true
+			}
+		}
+		return set, isAbsent, nil
+	case And:
+		return evaluateAnd(n, evaluator)
+	case Where:
+		return evaluate(n.Query, evaluator)
+	case Not:
+		operandSet, isAbsent, err := evaluate(n.Operand, evaluator)
+		if err != nil || isAbsent {
+			return recordSet{}, isAbsent, err
+		}
+		set = recordSet{}
+		for _, record := range evaluator.All() {
+			if !operandSet[record] {
+				set[record] = true
+			}
+		}
+		return set, false, nil
+	}
+	return recordSet{}, false, nil
+}
+
+//evaluateAnd intersects the operands; a NOT operand is subtracted from the other operands instead of being turned into the set of every other record
+func evaluateAnd(and And, evaluator Evaluator) (set recordSet, isAbsent bool, err error) {
+	excluded := []recordSet{}
+	for _, operand := range and.Operands {
+		not, isNot := operand.(Not)
+		if isNot {
+			operand = not.Operand
+		}
+		operandSet, isOperandAbsent, err := evaluate(operand, evaluator)
+		if err != nil {
+			return nil, false, err
+		}
+		if isOperandAbsent {
+			return recordSet{}, true, nil
+		}
+		if isNot {
+			excluded = append(excluded, operandSet)
+			continue
+		}
+		if set == nil {
+			set = operandSet
+			continue
+		}
+		for record := range set {
+			if !operandSet[record] {
+				delete(set, record)
+			}
+		}
+	}
+	if set == nil {
+		//Every operand is a NOT, ex. NOT tags:ohio AND NOT tags:utah
+		set = recordSet{}
+		for _, record := range evaluator.All() {
+			set[record] = true
+		}
+	}
+	for _, operandSet := range excluded {
+		for record := range operandSet {
+			delete(set, record)
+		}
+	}
+	return set, false, nil
+}
@@ -0,0 +1,83 @@
+package query_test
+
+import (
+	"reflect"
+	"searchDemo/src/query"
+	"testing"
+)
+
+//mockEvaluator answers the terms from the records of every field value, ex. {"status:pending": {1, 2}}; its records have no name field
+type mockEvaluator struct {
+	records map[string][]interface{}
+}
+
+func (e *mockEvaluator) Term(term query.Term) ([]interface{}, error) {
+	if term.Field == "name" {
+		return nil, query.ErrFieldAbsent
+	}
+	return e.records[term.Field+":"+term.Value], nil
+}
+
+func (e *mockEvaluator) All() []interface{} {
+	return []interface{}{1, 2, 3, 4}
+}
+
+func TestEvaluate(t *testing.T) {
+	evaluator := &mockEvaluator{records: map[string][]interface{}{
+		"status:pending": []interface{}{3, 1, 2},
+		"priority:high":  []interface{}{2, 3, 4},
+		"tags:ohio":      []interface{}{3},
+	}}
+	testCases := map[string]struct {
+		query           string
+		expectedRecords []interface{}
+	}{
+		"AND intersects, in the order of all records": {
+			query:           "status:pending AND priority:high",
+			expectedRecords: []interface{}{2, 3},
+		},
+		"AND NOT subtracts": {
+			query:           "status:pending AND priority:high AND NOT tags:ohio",
+			expectedRecords: []interface{}{2},
+		},
+		"OR unites": {
+			query:           "tags:ohio OR priority:high",
+			expectedRecords: []interface{}{2, 3, 4},
+		},
+		"NOT alone keeps the other records": {
+			query:           "NOT status:pending",
+			expectedRecords: []interface{}{4},
+		},
+		"AND of NOT only": {
+			query:           "NOT tags:ohio AND NOT priority:high",
+			expectedRecords: []interface{}{1},
+		},
+		"NOT on an absent field matches nothing": {
+			query: "NOT name:francisca",
+		},
+		"AND with a NOT on an absent field matches nothing": {
+			query: "NOT tags:ohio AND NOT name:francisca",
+		},
+		"OR with an absent field keeps the other operands": {
+			query:           "tags:ohio OR NOT name:francisca",
+			expectedRecords: []interface{}{3},
+		},
+		"unknown value": {
+			query: "status:closed OR tags:utah",
+		},
+	}
+	for tc, tp := range testCases {
+		node, err := query.Parse(tp.query)
+		if err != nil {
+			t.Fatalf("For test case <%s>, Expected the query is parsed, but actual error is <%v>", tc, err)
+		}
+		records, err := query.Evaluate(node, evaluator)
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(records, tp.expectedRecords) {
+			t.Errorf("For test case <%s>, Expected records are <%v>, but actual records are <%v>", tc, tp.expectedRecords, records)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenLeftParen
+	tokenRightParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenEnd
+)
+
+//token is a word, a quoted value, a parenthesis or an operator of a query; Column is the 1-based position of its first character
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+//operators are recognized in any case, ex. and or AND; quote them to search the words, ex. "or"
+var operators = map[string]tokenKind{"AND": tokenAnd, "OR": tokenOr, "NOT": tokenNot}
+
+//lex splits the query into tokens. A word ends at a space, a parenthesis or a quote, so status:"on hold" is the word status: followed by a quoted value.
+func lex(query string) (tokens []token, err error) {
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", column: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", column: i + 1})
+			i++
+		case r == '"':
+			start := i
+			value := strings.Builder{}
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				//\" and \\ keep a quote or a backslash in the value
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &ParseError{Query: query, Column: start + 1, Message: "unterminated quoted value"}
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenQuoted, text: value.String(), column: start + 1})
+		default:
+			start := i
+			for ; i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"`, runes[i]); i++ {
+			}
+			text := string(runes[start:i])
+			kind, ok := operators[strings.ToUpper(text)]
+			if !ok {
+				kind = tokenWord
+			}
+			tokens = append(tokens, token{kind: kind, text: text, column: start + 1})
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEnd, column: len(runes) + 1})
+	return
+}
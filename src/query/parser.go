@@ -0,0 +1,192 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//fieldPattern matches the field name in front of a value, ex. status in status:pending or organization.tags in organization.tags:fulton;
+//a word such as 2016-04-28T11:19:34 has no field name
+var fieldPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*):`)
+
+type parser struct {
+	query  string
+	tokens []token
+	next   int
+}
+
+//Parse reads a query such as status:pending AND priority:high AND NOT tags:ohio into its tree. NOT binds first, then AND, then OR;
+//terms written one after the other are joined by AND, parentheses group the operators, a quoted value may contain spaces, ex. subject:"a catastrophe",
+//and field:(a OR b) applies the field to every term of the group. A field may follow the relations of the records, ex. organization.tags:fulton,
+//and the query may be limited to one entity, ex. tickets where organization.tags:fulton and assignee.role:admin.
+//Parse returns a *ParseError pointing at the column of a syntax error.
+func Parse(query string) (node Node, err error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return
+	}
+	p := &parser{query: query, tokens: tokens}
+	if p.peek().kind == tokenEnd {
+		return nil, p.errorAt(p.peek(), "the query is empty")
+	}
+	entity, isScoped := p.parseScope()
+	node, err = p.parseOr("")
+	if err != nil {
+		return
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", describe(t)))
+	}
+	if isScoped {
+		node = Where{Entity: entity.text, Column: entity.column, Query: node}
+	}
+	return
+}
+
+//parseScope reads the entity in front of where, ex. tickets in tickets where status:pending
+func (p *parser) parseScope() (entity token, isScoped bool) {
+	if len(p.tokens) < 3 || p.tokens[0].kind != tokenWord || p.tokens[1].kind != tokenWord || !strings.EqualFold(p.tokens[1].text, "where") {
+		return
+	}
+	if strings.Contains(p.tokens[0].text, ":") {
+		return
+	}
+	entity = p.advance()
+	p.advance()
+	return entity, true
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, message string) *ParseError {
+	return &ParseError{Query: p.query, Column: t.column, Message: message}
+}
+
+//parseOr reads the terms joined by OR; field is the field of an enclosing field:( ) group, applied to the terms without a field
+func (p *parser) parseOr(field string) (Node, error) {
+	operands := []Node{}
+	for {
+		operand, err := p.parseAnd(field)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.advance()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return Or{Operands: operands}, nil
+}
+
+//parseAnd reads the terms joined by AND, or written one after the other
+func (p *parser) parseAnd(field string) (Node, error) {
+	operands := []Node{}
+	for {
+		operand, err := p.parseNot(field)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		next := p.peek().kind
+		if next == tokenAnd {
+			p.advance()
+			continue
+		}
+		if next != tokenWord && next != tokenQuoted && next != tokenLeftParen && next != tokenNot {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return And{Operands: operands}, nil
+}
+
+func (p *parser) parseNot(field string) (Node, error) {
+	if p.peek().kind != tokenNot {
+		return p.parsePrimary(field)
+	}
+	p.advance()
+	operand, err := p.parseNot(field)
+	if err != nil {
+		return nil, err
+	}
+	return Not{Operand: operand}, nil
+}
+
+//parsePrimary reads a group in parentheses or a term: value, "quoted value", field:value, field:"quoted value" or field:(group)
+func (p *parser) parsePrimary(field string) (Node, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokenLeftParen:
+		return p.parseGroup(t, field)
+	case tokenQuoted:
+		return Term{Field: field, Value: t.text, Column: t.column}, nil
+	case tokenWord:
+		match := fieldPattern.FindStringSubmatch(t.text)
+		if match == nil {
+			return Term{Field: field, Value: t.text, Column: t.column}, nil
+		}
+		if len(field) != 0 {
+			return nil, p.errorAt(t, fmt.Sprintf("the field %s is set inside the group of the field %s", match[1], field))
+		}
+		value := t.text[len(match[0]):]
+		if len(value) != 0 {
+			return Term{Field: match[1], Value: value, Column: t.column}, nil
+		}
+		//The value follows the colon as its own token, ex. status:"on hold" or status:(pending OR open)
+		next := p.peek()
+		if next.column != t.column+len([]rune(t.text)) {
+			return nil, p.errorAt(next, fmt.Sprintf("expected a value after %s", t.text))
+		}
+		switch next.kind {
+		case tokenQuoted:
+			p.advance()
+			return Term{Field: match[1], Value: next.text, Column: t.column}, nil
+		case tokenLeftParen:
+			p.advance()
+			return p.parseGroup(next, match[1])
+		}
+		return nil, p.errorAt(next, fmt.Sprintf("expected a value after %s", t.text))
+	}
+	return nil, p.errorAt(t, fmt.Sprintf("expected a term, but found %s", describe(t)))
+}
+
+//parseGroup reads the query in parentheses after the opening parenthesis
+func (p *parser) parseGroup(open token, field string) (Node, error) {
+	node, err := p.parseOr(field)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenRightParen {
+		return nil, p.errorAt(p.peek(), fmt.Sprintf("expected ) to close the ( at column %d, but found %s", open.column, describe(p.peek())))
+	}
+	p.advance()
+	return node, nil
+}
+
+//describe names a token in the errors
+func describe(t token) string {
+	switch t.kind {
+	case tokenEnd:
+		return "the end of the query"
+	case tokenQuoted:
+		return fmt.Sprintf("%q", t.text)
+	}
+	return strings.TrimSpace(t.text)
+}
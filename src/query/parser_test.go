@@ -0,0 +1,132 @@
+package query_test
+
+import (
+	"searchDemo/src/query"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := map[string]struct {
+		query         string
+		expectedQuery string
+	}{
+		"NOT binds before AND, AND before OR": {
+			query:         "status:pending AND priority:high OR NOT tags:ohio",
+			expectedQuery: "((status:pending AND priority:high) OR NOT tags:ohio)",
+		},
+		"terms one after the other are joined by AND": {
+			query:         "status:pending priority:high",
+			expectedQuery: "(status:pending AND priority:high)",
+		},
+		"parentheses group the operators": {
+			query:         "status:pending AND (priority:high OR priority:urgent)",
+			expectedQuery: "(status:pending AND (priority:high OR priority:urgent))",
+		},
+		"quoted values keep their spaces": {
+			query:         `subject:"a catastrophe" OR "Francisca Rasmussen"`,
+			expectedQuery: `(subject:"a catastrophe" OR "Francisca Rasmussen")`,
+		},
+		"field group applies the field to every value": {
+			query:         `tags:(ohio OR "new york") AND NOT status:closed`,
+			expectedQuery: `((tags:ohio OR tags:"new york") AND NOT status:closed)`,
+		},
+		"values keep their colons and operators": {
+			query:         "created_at:2016-04-28T11:19:34 due_at:<now",
+			expectedQuery: "(created_at:2016-04-28T11:19:34 AND due_at:<now)",
+		},
+		"operators in any case": {
+			query:         "ohio or utah and not texas",
+			expectedQuery: "(ohio OR (utah AND NOT texas))",
+		},
+		"quoted operators are words": {
+			query:         `ohio "or" utah`,
+			expectedQuery: "(ohio AND or AND utah)",
+		},
+		"fields of linked records": {
+			query:         "organization.tags:fulton AND assignee.role:admin",
+			expectedQuery: "(organization.tags:fulton AND assignee.role:admin)",
+		},
+		"where limits the query to one entity": {
+			query:         "tickets where organization.tags:fulton or status:pending",
+			expectedQuery: "tickets where (organization.tags:fulton OR status:pending)",
+		},
+	}
+	for tc, tp := range testCases {
+		node, err := query.Parse(tp.query)
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error, but actual error is <%v>", tc, err)
+			continue
+		}
+		if node.String() != tp.expectedQuery {
+			t.Errorf("For test case <%s>, Expected query is <%s>, but actual query is <%s>", tc, tp.expectedQuery, node.String())
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	testCases := map[string]struct {
+		query           string
+		expectedColumn  int
+		expectedMessage string
+	}{
+		"empty query": {
+			query:           "  ",
+			expectedColumn:  3,
+			expectedMessage: "the query is empty",
+		},
+		"missing term after AND": {
+			query:           "status:pending AND",
+			expectedColumn:  19,
+			expectedMessage: "expected a term, but found the end of the query",
+		},
+		"unclosed parenthesis": {
+			query:           "status:pending AND (priority:high OR tags:ohio",
+			expectedColumn:  47,
+			expectedMessage: "expected ) to close the ( at column 20, but found the end of the query",
+		},
+		"unexpected closing parenthesis": {
+			query:           "status:pending) OR tags:ohio",
+			expectedColumn:  15,
+			expectedMessage: "unexpected )",
+		},
+		"unterminated quoted value": {
+			query:           `status:pending AND subject:"a catastrophe`,
+			expectedColumn:  28,
+			expectedMessage: "unterminated quoted value",
+		},
+		"field without a value": {
+			query:           "status: pending",
+			expectedColumn:  9,
+			expectedMessage: "expected a value after status:",
+		},
+		"where without a query": {
+			query:           "tickets where",
+			expectedColumn:  14,
+			expectedMessage: "expected a term, but found the end of the query",
+		},
+		"field inside a field group": {
+			query:           "tags:(ohio OR status:pending)",
+			expectedColumn:  15,
+			expectedMessage: "the field status is set inside the group of the field tags",
+		},
+	}
+	for tc, tp := range testCases {
+		_, err := query.Parse(tp.query)
+		parseErr, ok := err.(*query.ParseError)
+		if !ok {
+			t.Errorf("For test case <%s>, Expected a parse error, but actual error is <%v>", tc, err)
+			continue
+		}
+		if parseErr.Column != tp.expectedColumn || parseErr.Message != tp.expectedMessage {
+			t.Errorf("For test case <%s>, Expected error <%s> at column <%d>, but actual error is <%s> at column <%d>", tc, tp.expectedMessage, tp.expectedColumn, parseErr.Message, parseErr.Column)
+		}
+	}
+}
+
+func TestParseErrorPointer(t *testing.T) {
+	_, err := query.Parse("status:pending AND")
+	expectedPointer := "status:pending AND\n                  ^"
+	if pointer := err.(*query.ParseError).Pointer(); pointer != expectedPointer {
+		t.Errorf("Expected pointer is <%s>, but actual pointer is <%s>", expectedPointer, pointer)
+	}
+}
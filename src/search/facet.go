@@ -0,0 +1,222 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"searchDemo/src/data"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+//facetsPattern finds the facets clause ending a search value, ex. pending facets=priority,tags; facets without field names counts the default facets of each entity
+var facetsPattern = regexp.MustCompile(`(?i)(?:^|\s)(facets(?:\s*=|\s*$))`)
+
+//Facet counts the matched records of an entity by the values of a field, the most frequent value first; Missing counts the matched records without a value
+type Facet struct {
+	Field   string       `json:"field"`
+	Counts  []FacetCount `json:"counts"`
+	Missing int          `json:"missing,omitempty"`
+}
+
+//FacetCount is the number of matched records with a value of the facet field
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+//splitFacets reads the facets clause at the end of a search value, ex. pending facets=status, priority; names is empty for the default facets.
+//The last facets outside quotes starts the clause; value is the search value without the clause.
+func splitFacets(input string) (value string, names []string, isFaceted bool, err error) {
+	start, end := facetsClause(input)
+	if start < 0 {
+		return input, nil, false, nil
+	}
+	for _, part := range strings.Split(input[end:], ",") {
+		name := strings.TrimSpace(part)
+		if len(name) == 0 {
+			continue
+		}
+		if strings.ContainsAny(name, " \t") {
+			return "", nil, true, fmt.Errorf("invalid facet field %q, expected field names separated by commas, ex. facets=status,priority, after select and before sort by and limit", name)
+		}
+		names = append(names, name)
+	}
+	return strings.TrimSpace(input[:start]), names, true, nil
+}
+
+//facetsClause returns the byte offsets of the facets keyword and of the first field after it, or -1 when there is no facets clause
+func facetsClause(input string) (start, end int) {
+	start, end = -1, -1
+	for _, match := range facetsPattern.FindAllStringSubmatchIndex(input, -1) {
+		if strings.Count(input[:match[2]], `"`)%2 == 0 {
+			start, end = match[2], match[3]
+		}
+	}
+	return
+}
+
+//checkFacetFields returns a NotFoundError with the closest field names for the first facet field which is not a field of any of the field maps,
+//and an error for a text field, whose words are not values to count
+func checkFacetFields(names []string, fieldMaps ...map[string]data.Field) error {
+	for _, name := range names {
+		fieldKey := data.FieldKey(name)
+		fieldNames := []string{}
+		isKnown := false
+		for _, fieldMap := range fieldMaps {
+			field, ok := fieldMap[fieldKey]
+			if ok && field.Type == "text" {
+				return fmt.Errorf("facet field %s is a text field, expected a field with a few distinct values such as status or tags", name)
+			}
+			isKnown = isKnown || ok
+			fieldNames = append(fieldNames, sortedFieldKeys(fieldMap)...)
+		}
+		if !isKnown {
+			return &NotFoundError{Message: fmt.Sprintf("No facet field %s found", name), Suggestions: suggestNames(fieldNames, fieldKey)}
+		}
+	}
+	return nil
+}
+
+//facetCount is a count of a facet value with the sort key of its first matched record, which orders the values with the same count
+type facetCount struct {
+	FacetCount
+	key data.SortKey
+}
+
+//countFacets counts the records of the entity by the values of each facet field, intersecting the posting list of every value of the field with the records.
+//The facet fields are the named ones, or the default facets of the entity when none is named; a field the entity does not have is left out.
+//Values with the same count are ordered like the field is sorted, ex. numbers by value and timestamps chronologically.
+func countFacets(entity data.Entity, records []interface{}, names []string, fieldMap map[string]data.Field) (facets []Facet) {
+	fieldKeys := entity.Facets
+	if len(names) != 0 {
+		fieldKeys = []string{}
+		for _, name := range names {
+			fieldKeys = append(fieldKeys, data.FieldKey(name))
+		}
+	}
+	isMatched := make(map[interface{}]bool, len(records))
+	for _, record := range records {
+		isMatched[record] = true
+	}
+	for _, fieldKey := range fieldKeys {
+		field, ok := fieldMap[fieldKey]
+		if !ok {
+			continue
+		}
+		facet := Facet{Field: fieldKey, Counts: []FacetCount{}}
+		counts := []facetCount{}
+		for value, postings := range field.ValueMap {
+			//The blank values are counted as missing below
+			if len(value) == 0 {
+				continue
+			}
+			count := facetCount{FacetCount: FacetCount{Value: value}}
+			for _, record := range postings {
+				if !isMatched[record] {
+					continue
+				}
+				if count.Count == 0 {
+					count.Value = facetLabel(field, value, record)
+					count.key = field.SortKey(record)
+				}
+				count.Count++
+			}
+			if count.Count > 0 {
+				counts = append(counts, count)
+			}
+		}
+		for _, record := range field.Empty() {
+			if isMatched[record] {
+				facet.Missing++
+			}
+		}
+		//The values of a list field are its elements, while the sort key of a record is its whole list, so they are tied alphabetically
+		isList := field.Type == "[]string"
+		sort.Slice(counts, func(i, j int) bool {
+			if counts[i].Count != counts[j].Count {
+				return counts[i].Count > counts[j].Count
+			}
+			if !isList {
+				if c := field.CompareKeys(counts[i].key, counts[j].key); c != 0 {
+					return c < 0
+				}
+			}
+			return strings.ToLower(counts[i].Value) < strings.ToLower(counts[j].Value)
+		})
+		for _, count := range counts {
+			facet.Counts = append(facet.Counts, count.FacetCount)
+		}
+		facets = append(facets, facet)
+	}
+	return
+}
+
+//facetLabel returns the value as it is written in the record, ex. South Carolina for the indexed value south carolina
+func facetLabel(field data.Field, value string, record interface{}) string {
+	switch v := field.Value(record).(type) {
+	case string:
+		if strings.EqualFold(v, value) {
+			return v
+		}
+	case []string:
+		for _, element := range v {
+			if strings.EqualFold(element, value) {
+				return element
+			}
+		}
+	}
+	return value
+}
+
+//FacetSearch func retrieves a query from the user and prints the counts of its matched records by the values of the facet fields as a table,
+//by the default facets of each entity unless the query ends with a facets clause, ex. status:pending facets=priority,via
+func (s *service) FacetSearch() (results interface{}, isQuit bool, err error) {
+	fmt.Println("Please enter the query to count, ex. status:pending; end it with facets= and the fields to count, ex. status:pending facets=priority,via, or the default fields of each resource are counted")
+	isQuit, input := s.InteractionService.GetUserInput()
+	if isQuit {
+		return
+	}
+	//The counts do not depend on the page or the order of the results
+	value, _, err := splitPage(input)
+	if err != nil {
+		return
+	}
+	value, _, err = splitSort(value)
+	if err != nil {
+		return
+	}
+	if start, _ := facetsClause(value); start < 0 {
+		value += " facets"
+	}
+	results, err = s.Query(value)
+	if err != nil {
+		return
+	}
+	page, _ := results.(Page)
+	printFacets(page)
+	return nil, false, nil
+}
+
+//printFacets prints the facets of a page as a table of the resource, the field, the value and the count
+func printFacets(page Page) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tFIELD\tVALUE\tCOUNT")
+	for _, entity := range data.Entities() {
+		facets, ok := page.Facets[entity.Name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t\t(matched)\t%d\n", entity.Name, page.Totals[entity.Name])
+		for _, facet := range facets {
+			for _, count := range facet.Counts {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", entity.Name, facet.Field, count.Value, count.Count)
+			}
+			if facet.Missing > 0 {
+				fmt.Fprintf(w, "%s\t%s\t(missing)\t%d\n", entity.Name, facet.Field, facet.Missing)
+			}
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"searchDemo/src/data"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//maxSuggestions is the number of suggestions given for a search without results
+const maxSuggestions = 5
+
+//fuzzyPattern matches a fuzzy search value, ex. fransisca~ tolerates the default number of typos and fransisca~1 a single typo
+var fuzzyPattern = regexp.MustCompile(`^(.*\S)\s*~(\d)?$`)
+
+//NotFoundError is returned when a search finds nothing. Suggestions are the indexed values, field names or entity names closest to what was typed,
+//ex. francisca rasmussen for fransisca rasmussen.
+type NotFoundError struct {
+	Message     string
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s. Did you mean: %s?", e.Message, strings.Join(e.Suggestions, ", "))
+}
+
+//parseFuzzy reads a fuzzy search value; the distance is the number of typos given after the ~, or data.FuzzyDistance when it is not given
+func parseFuzzy(value string) (term string, distance int, isFuzzy bool) {
+	match := fuzzyPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return
+	}
+	distance = data.FuzzyDistance
+	if len(match[2]) != 0 {
+		distance, _ = strconv.Atoi(match[2])
+	}
+	return match[1], distance, true
+}
+
+//suggestValues returns the values of the fields closest to the search value, the closest first; a pattern or a range has no suggestion
+func suggestValues(fields []data.Field, value string) []string {
+	if _, isPattern, _ := parsePattern(value); isPattern {
+		return nil
+	}
+	term, distance, isFuzzy := parseFuzzy(value)
+	if !isFuzzy {
+		term, distance = value, data.FuzzyDistance
+	}
+	suggestions := []data.Suggestion{}
+	for _, field := range fields {
+		suggestions = append(suggestions, field.Suggest(term, distance)...)
+	}
+	return bestSuggestions(suggestions)
+}
+
+//suggestNames returns the names closest to a field or entity name which is not found, ex. submitterid for submiterid
+func suggestNames(names []string, name string) []string {
+	sort.Strings(names)
+	suggestions := []data.Suggestion{}
+	for _, n := range names {
+		distance := data.Distance(strings.ToLower(name), n, data.FuzzyDistance)
+		if distance <= data.FuzzyDistance {
+			suggestions = append(suggestions, data.Suggestion{Value: n, Distance: distance})
+		}
+	}
+	return bestSuggestions(suggestions)
+}
+
+//bestSuggestions orders the suggestions by distance and then by value, and keeps the first maxSuggestions different values
+func bestSuggestions(suggestions []data.Suggestion) (values []string) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Distance != suggestions[j].Distance {
+			return suggestions[i].Distance < suggestions[j].Distance
+		}
+		return suggestions[i].Value < suggestions[j].Value
+	})
+	isAdded := map[string]bool{}
+	for _, suggestion := range suggestions {
+		if len(values) == maxSuggestions {
+			break
+		}
+		if !isAdded[suggestion.Value] {
+			isAdded[suggestion.Value] = true
+			values = append(values, suggestion.Value)
+		}
+	}
+	return
+}
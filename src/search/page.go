@@ -0,0 +1,278 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"searchDemo/src/data"
+	"strconv"
+	"strings"
+)
+
+//PageSize is the number of results per page when a search gives no limit, set by the page-size setting; every result is returned on one page when it is 0
+var PageSize = 0
+
+//pagePattern finds a limit or offset clause with a number, or a cursor clause, ending a search value, ex. pending limit 20 offset 40
+var pagePattern = regexp.MustCompile(`(?i)(?:^|\s)(limit\s+[-+]?\d+|offset\s+[-+]?\d+|cursor\s+\S+)\s*$`)
+
+//The search paths a cursor continues
+const (
+	pathValue = "value"
+	pathField = "field"
+	pathQuery = "query"
+)
+
+//Page is one page of the results of a search. Results is a part of the list of a field specific search, or of the results keyed by entity of a direct value search or a query,
+//counted across the entities in the order they are listed. Total is the number of results on every page, and Totals the number of each entity.
+//NextCursor and PreviousCursor continue the same search on the pages around this one; they are empty on the last and the first page.
+//Facets are the counts of the results of every page by entity, when the search asks for them.
+type Page struct {
+	Results        interface{}        `json:"results"`
+	Total          int                `json:"total"`
+	Totals         map[string]int     `json:"totals,omitempty"`
+	Offset         int                `json:"offset"`
+	Limit          int                `json:"limit"`
+	NextCursor     string             `json:"next_cursor,omitempty"`
+	PreviousCursor string             `json:"previous_cursor,omitempty"`
+	Facets         map[string][]Facet `json:"facets,omitempty"`
+}
+
+//pageRequest is the page asked by the page clause of a search value; limit is -1 when the clause gives none, so the PageSize is used
+type pageRequest struct {
+	offset int
+	limit  int
+	cursor string
+	isSet  bool
+}
+
+//cursorState is the search continued by a cursor: its path, the entity and field of a field specific search, the value without its page clause, and the page.
+//Digest identifies the data the cursor was issued on, as the results move between the pages once the data files change.
+type cursorState struct {
+	Path   string `json:"p"`
+	Entity string `json:"e,omitempty"`
+	Field  string `json:"f,omitempty"`
+	Value  string `json:"v"`
+	Offset int    `json:"o"`
+	Limit  int    `json:"l"`
+	Digest string `json:"d"`
+}
+
+//splitPage reads the page clause at the end of a search value, ex. pending sort by priority limit 20 offset 40, or cursor eyJwIjoidmFsdWUi...;
+//value is the search value without the clause. A cursor holds its whole search, so it is given alone.
+func splitPage(input string) (value string, request pageRequest, err error) {
+	request = pageRequest{limit: -1}
+	start := pageClause(input)
+	if start < 0 {
+		return input, request, nil
+	}
+	request.isSet = true
+	words := strings.Fields(input[start:])
+	isGiven := map[string]bool{}
+	for i := 0; i+1 < len(words); i += 2 {
+		keyword, number := strings.ToLower(words[i]), words[i+1]
+		if isGiven[keyword] {
+			return "", request, fmt.Errorf("%s is given more than once", keyword)
+		}
+		isGiven[keyword] = true
+		switch keyword {
+		case "cursor":
+			request.cursor = number
+		case "limit":
+			request.limit, err = strconv.Atoi(number)
+			if err != nil || request.limit <= 0 {
+				return "", request, fmt.Errorf("invalid limit %q, expected a positive number of results", number)
+			}
+		case "offset":
+			request.offset, err = strconv.Atoi(number)
+			if err != nil || request.offset < 0 {
+				return "", request, fmt.Errorf("invalid offset %q, expected the number of results to skip", number)
+			}
+		}
+	}
+	value = strings.TrimSpace(input[:start])
+	if len(request.cursor) != 0 && (len(words) != 2 || len(value) != 0) {
+		return "", request, errors.New("a cursor continues the search it was printed with, type it alone, ex. cursor eyJwIjoidmFsdWUi...")
+	}
+	return value, request, nil
+}
+
+//pageClause returns the byte offset of the first keyword of the page clause ending the input, or -1 when there is no page clause.
+//A keyword inside quotes is part of the value, and so is a limit or offset without a value in front of it, ex. a search for "limit 10";
+//a cursor is the only clause given alone.
+func pageClause(input string) (start int) {
+	start = -1
+	rest := input
+	for {
+		match := pagePattern.FindStringSubmatchIndex(rest)
+		if match == nil || strings.Count(rest[:match[2]], `"`)%2 != 0 {
+			break
+		}
+		start, rest = match[2], rest[:match[0]]
+	}
+	if start >= 0 && len(strings.TrimSpace(rest)) == 0 && !strings.HasPrefix(strings.ToLower(input[start:]), "cursor") {
+		return -1
+	}
+	return
+}
+
+//paginate returns the page of the results asked by the request, or the results unchanged when the request gives no page, the results are not paged by default
+//and there are no facets to show with them. The results are the list of a field specific search or the results keyed by entity; state is their search, so the cursors of the page continue it.
+func (s *service) paginate(state cursorState, results interface{}, facets map[string][]Facet, request pageRequest) interface{} {
+	limit := request.limit
+	if limit < 0 {
+		limit = PageSize
+	}
+	if !request.isSet && limit == 0 && facets == nil {
+		return results
+	}
+	page := Page{Offset: request.offset, Limit: limit, Facets: facets}
+	switch r := results.(type) {
+	case []interface{}:
+		start, end := pageBounds(request.offset, limit, len(r))
+		page.Results, page.Total = r[start:end], len(r)
+	case map[string][]interface{}:
+		pageResults := map[string][]interface{}{}
+		page.Totals = map[string]int{}
+		for _, entity := range data.Entities() {
+			list, ok := r[entity.Name]
+			if !ok {
+				continue
+			}
+			start, end := pageBounds(request.offset-page.Total, limit, len(list))
+			if start < end {
+				pageResults[entity.Name] = list[start:end]
+			}
+			page.Totals[entity.Name] = len(list)
+			page.Total += len(list)
+		}
+		page.Results = pageResults
+	}
+
+	state.Limit, state.Digest = limit, s.dataDigest()
+	if limit > 0 && request.offset+limit < page.Total {
+		state.Offset = request.offset + limit
+		page.NextCursor = encodeCursor(state)
+	}
+	if request.offset > 0 {
+		state.Offset = request.offset - limit
+		if limit > 0 && state.Offset >= page.Total {
+			//The page before an offset past the results is the last page
+			state.Offset = (page.Total - 1) / limit * limit
+		}
+		if state.Offset < 0 || limit == 0 {
+			state.Offset = 0
+		}
+		page.PreviousCursor = encodeCursor(state)
+	}
+	return page
+}
+
+//pageBounds returns the part of count results on the page starting at offset; the offset is negative when the page starts before the results, ie. in a previous entity
+func pageBounds(offset, limit, count int) (start, end int) {
+	start = offset
+	if start < 0 {
+		start = 0
+	}
+	if start > count {
+		start = count
+	}
+	end = count
+	if limit > 0 && offset+limit < count {
+		end = offset + limit
+	}
+	if end < start {
+		end = start
+	}
+	return
+}
+
+//encodeCursor turns the state into the opaque cursor printed with a page
+func encodeCursor(state cursorState) string {
+	content, _ := json.Marshal(state)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+//decodeCursor reads the state of a cursor printed with a page
+func decodeCursor(cursor string) (state cursorState, err error) {
+	content, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(content, &state)
+	}
+	if err != nil || len(state.Path) == 0 {
+		return state, errors.New("invalid cursor, expected a next_cursor or previous_cursor printed with the results of a search")
+	}
+	return state, nil
+}
+
+//dataDigest identifies the loaded data by the fingerprints of its files
+func (s *service) dataDigest() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	h := fnv.New32a()
+	for _, source := range s.Sources {
+		fmt.Fprintf(h, "%s|%s|%d|%d;", source.Entity, source.Path, source.Size, source.ModTime)
+	}
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+//Page func continues the search of a cursor printed with a page of results, ex. its next_cursor, and returns the page the cursor points at.
+//A cursor is refused once the data files changed, as the results may have moved to other pages.
+func (s *service) Page(cursor string) (results interface{}, err error) {
+	state, err := decodeCursor(cursor)
+	if err != nil {
+		return
+	}
+	if state.Digest != s.dataDigest() {
+		return nil, errors.New("the cursor has expired because the data files changed, run the search again")
+	}
+	value := fmt.Sprintf("%s offset %d", state.Value, state.Offset)
+	if state.Limit > 0 {
+		value = fmt.Sprintf("%s limit %d offset %d", state.Value, state.Limit, state.Offset)
+	}
+	switch state.Path {
+	case pathField:
+		return s.SearchField(state.Entity, state.Field, value)
+	case pathQuery:
+		return s.Query(value)
+	}
+	return s.searchValue(value)
+}
+
+//RequestPage func asks whether to browse to the next or the previous page of the results; cursor is empty when the user leaves the pages
+func (s *service) RequestPage(page Page) (cursor string, isQuit bool) {
+	if len(page.NextCursor) == 0 && len(page.PreviousCursor) == 0 {
+		return
+	}
+	if page.count() == 0 {
+		fmt.Printf("There are no results on this page, the search has %d results. ", page.Total)
+	} else {
+		fmt.Printf("Showing results %d to %d of %d. ", page.Offset+1, page.Offset+page.count(), page.Total)
+	}
+	fmt.Println("Type 'next' for the next page, 'prev' for the previous page, or any other key to continue")
+	isQuit, input := s.InteractionService.GetUserInput()
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "next":
+		cursor = page.NextCursor
+	case "prev", "previous":
+		cursor = page.PreviousCursor
+	}
+	return
+}
+
+//count returns the number of results on the page
+func (p Page) count() int {
+	switch r := p.Results.(type) {
+	case []interface{}:
+		return len(r)
+	case map[string][]interface{}:
+		count := 0
+		for _, list := range r {
+			count += len(list)
+		}
+		return count
+	}
+	return 0
+}
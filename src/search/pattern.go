@@ -0,0 +1,47 @@
+package search
+
+import (
+	"searchDemo/src/data"
+	"strings"
+)
+
+//parsePattern reads a pattern search value: /expression/ is a regular expression, and a value with * is a wildcard, ex. fran* or *@flotonic.com.
+//isPattern is false for the other values, which are searched as plain values.
+func parsePattern(value string) (pattern data.Pattern, isPattern bool, err error) {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) > 2 && strings.HasPrefix(trimmed, "/") && strings.HasSuffix(trimmed, "/") {
+		pattern, err = data.RegexpPattern(trimmed[1 : len(trimmed)-1])
+		return pattern, true, err
+	}
+	if strings.Contains(trimmed, "*") {
+		pattern, err = data.WildcardPattern(trimmed)
+		return pattern, true, err
+	}
+	return
+}
+
+//splitFieldName reads the field a direct search value is limited to, ex. name:fran* searches fran* in the name field of every entity which has one.
+//fieldKey is empty when the value does not start with the name of a field, such as a timestamp or a url.
+func splitFieldName(value string, structMap map[string]map[string]data.Field) (fieldKey, fieldValue string) {
+	//A set may also follow the field name, ex. status in (pending, hold) or priority != low
+	fieldName, set, isSet := splitSetField(value)
+	if isSet && len(fieldName) != 0 {
+		key := data.FieldKey(fieldName)
+		for _, fieldMap := range structMap {
+			if _, ok := fieldMap[key]; ok {
+				return key, set
+			}
+		}
+	}
+	end := strings.Index(value, ":")
+	if end <= 0 {
+		return "", value
+	}
+	key := data.FieldKey(strings.TrimSpace(value[:end]))
+	for _, fieldMap := range structMap {
+		if _, ok := fieldMap[key]; ok {
+			return key, strings.TrimSpace(value[end+1:])
+		}
+	}
+	return "", value
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"searchDemo/src/data"
+	"strings"
+)
+
+//presenceOperators test whether the records have a value for the field given after them, ex. has:due_at or missing:assignee_id
+var presenceOperators = map[string]bool{"has": true, "missing": true}
+
+//parsePresence returns the operator and the field name of a has: or missing: search value
+func parsePresence(value string) (operator, fieldName string, isPresence bool) {
+	end := strings.Index(value, ":")
+	if end <= 0 {
+		return
+	}
+	operator = strings.ToLower(strings.TrimSpace(value[:end]))
+	if !presenceOperators[operator] {
+		return "", "", false
+	}
+	return operator, strings.TrimSpace(value[end+1:]), true
+}
+
+//presenceRecords returns the records missing the field in their data file for missing, or the records of all which are neither missing it nor blank for has
+func presenceRecords(operator string, field data.Field, all []interface{}) (records []interface{}) {
+	if operator == "missing" {
+		return field.Missing
+	}
+	isEmpty := map[interface{}]bool{}
+	for _, record := range field.Empty() {
+		isEmpty[record] = true
+	}
+	for _, record := range all {
+		if !isEmpty[record] {
+			records = append(records, record)
+		}
+	}
+	return
+}
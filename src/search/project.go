@@ -0,0 +1,77 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"searchDemo/src/data"
+	"strings"
+)
+
+//selectPattern finds the select clause ending a search value, ex. pending select subject, assignee_name; fields= starts the same clause, ex. pending fields=subject,assignee_name
+var selectPattern = regexp.MustCompile(`(?i)(?:^|\s)(select(?:\s|$)|fields\s*=)`)
+
+//splitSelect reads the select clause at the end of a search value, ex. pending select subject, assignee_name, organization.name. The last select outside quotes starts the clause,
+//so a quoted value may contain the word; value is the search value without the clause.
+func splitSelect(input string) (value string, paths []string, err error) {
+	start, end := selectClause(input)
+	if start < 0 {
+		return input, nil, nil
+	}
+	for _, part := range strings.Split(input[end:], ",") {
+		path := strings.TrimSpace(part)
+		if len(path) == 0 {
+			continue
+		}
+		if strings.ContainsAny(path, " \t") {
+			return "", nil, fmt.Errorf("invalid select field %q, expected attribute names or dotted paths separated by commas, ex. select subject, assignee.email", path)
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return "", nil, errors.New("expected fields after select, ex. select subject, assignee_name, organization.name")
+	}
+	return strings.TrimSpace(input[:start]), paths, nil
+}
+
+//selectClause returns the byte offsets of the select keyword and of the first field after it, or -1 when there is no select clause
+func selectClause(input string) (start, end int) {
+	start, end = -1, -1
+	for _, match := range selectPattern.FindAllStringSubmatchIndex(input, -1) {
+		if strings.Count(input[:match[2]], `"`)%2 == 0 {
+			start, end = match[2], match[3]
+		}
+	}
+	return
+}
+
+//checkSelectFields returns a NotFoundError with the closest attribute names for the first selected path which is not an attribute of any of the entities
+func checkSelectFields(paths []string, entities ...data.Entity) error {
+	for _, path := range paths {
+		isKnown := false
+		for _, entity := range entities {
+			isKnown = isKnown || entity.HasAttribute(path)
+		}
+		if !isKnown {
+			return &NotFoundError{Message: fmt.Sprintf("No select field %s found", path), Suggestions: suggestNames(attributeNames(entities), strings.ToLower(path))}
+		}
+	}
+	return nil
+}
+
+//attributeNames lists the attributes of the entities and of the entities their relations lead to, ex. subject and assignee.email, for the suggestions
+func attributeNames(entities []data.Entity) (names []string) {
+	for _, entity := range entities {
+		names = append(names, entity.Attributes()...)
+		for _, relation := range entity.Relations {
+			target, ok := data.LookupEntity(relation.Target)
+			if !ok {
+				continue
+			}
+			for _, name := range target.Attributes() {
+				names = append(names, data.FieldKey(relation.Name)+"."+name)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,297 @@
+package search
+
+import (
+	"fmt"
+	"searchDemo/src/data"
+	"searchDemo/src/query"
+	"strings"
+)
+
+//QuerySearch func retrieves a query from the user, ex. status:pending AND priority:high AND NOT tags:ohio, and returns the matched records of every entity
+func (s *service) QuerySearch() (results interface{}, isQuit bool, err error) {
+	fmt.Println("Please enter the query, ex. status:pending AND priority:high AND NOT tags:ohio")
+	fmt.Println("Combine field:value terms with AND, OR, NOT and parentheses; quote values with spaces, ex. subject:\"a catastrophe\", and group the values of a field, ex. status:(pending OR open)")
+	isQuit, input := s.InteractionService.GetUserInput()
+	if isQuit {
+		return
+	}
+	results, err = s.Query(input)
+	return
+}
+
+//Query func evaluates a boolean query against the indexes of every entity, or of the entity given in front of where, ex. tickets where organization.tags:fulton.
+//The results are keyed by the entity name like a direct value search, in the order of the entity IDs; an entity without the fields of a term does not match it,
+//nor its NOT, ex. NOT status:pending only finds tickets.
+//A syntax error, an unknown field or an invalid value is returned as a *query.ParseError pointing at the column of the query.
+//The query may end with a select clause, ex. status:pending select subject, assignee_name, then with a facets clause, ex. status:pending facets=priority,via,
+//a sort clause, ex. status:pending sort by created_at desc, priority, and a page clause, ex. status:pending limit 20 offset 40.
+func (s *service) Query(q string) (results interface{}, err error) {
+	input, request, err := splitPage(q)
+	if err != nil {
+		return nil, &query.ParseError{Query: q, Column: len([]rune(q[:pageClause(q)])) + 1, Message: err.Error()}
+	}
+	if len(request.cursor) != 0 {
+		return s.Page(request.cursor)
+	}
+	value, sortKeys, err := splitSort(input)
+	if err != nil {
+		start, _ := sortClause(q)
+		return nil, &query.ParseError{Query: q, Column: len([]rune(q[:start])) + 1, Message: err.Error()}
+	}
+	value, facetNames, isFaceted, err := splitFacets(value)
+	if err != nil {
+		start, _ := facetsClause(q)
+		return nil, &query.ParseError{Query: q, Column: len([]rune(q[:start])) + 1, Message: err.Error()}
+	}
+	value, paths, err := splitSelect(value)
+	if err != nil {
+		start, _ := selectClause(q)
+		return nil, &query.ParseError{Query: q, Column: len([]rune(q[:start])) + 1, Message: err.Error()}
+	}
+	node, err := query.Parse(value)
+	if err != nil {
+		return
+	}
+	structMap := s.GetStructMap()
+	entities, err := queryEntities(value, node)
+	if err != nil {
+		return
+	}
+	err = checkQueryFields(value, node, entities, structMap)
+	if err != nil {
+		return
+	}
+	fieldMaps := []map[string]data.Field{}
+	for _, entity := range entities {
+		fieldMaps = append(fieldMaps, structMap[entity.Name])
+	}
+	err = checkSortFields(sortKeys, fieldMaps...)
+	if err != nil {
+		return
+	}
+	err = checkFacetFields(facetNames, fieldMaps...)
+	if err != nil {
+		return
+	}
+	err = checkSelectFields(paths, entities...)
+	if err != nil {
+		return
+	}
+	combinedResultsMap := map[string][]interface{}{}
+	var facets map[string][]Facet
+	if isFaceted {
+		facets = map[string][]Facet{}
+	}
+	var firstErr error
+	for _, entity := range entities {
+		fieldMap, ok := structMap[entity.Name]
+		if !ok {
+			continue
+		}
+		records, e := query.Evaluate(node, &entityEvaluator{query: value, entity: entity, fieldMap: fieldMap, structMap: structMap})
+		if e != nil {
+			//An invalid value for one entity, ex. id:abc for the int ids of users, is only an error when no entity matches
+			if firstErr == nil {
+				firstErr = e
+			}
+			continue
+		}
+		if len(records) == 0 {
+			continue
+		}
+		sortResults(entity, records, nil, sortKeys, fieldMap)
+		if isFaceted {
+			facets[entity.Name] = countFacets(entity, records, facetNames, fieldMap)
+		}
+		combinedResultsMap[entity.Name], err = processResults(entity.Name, records, nil, paths, structMap)
+		if err != nil {
+			return
+		}
+	}
+	if len(combinedResultsMap) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, &NotFoundError{Message: "No results returned"}
+	}
+	return s.paginate(cursorState{Path: pathQuery, Value: input}, combinedResultsMap, facets, request), nil
+}
+
+//queryEntities returns the entity given in front of where, compared with the entity names and titles, or every entity when the query has no where
+func queryEntities(q string, node query.Node) ([]data.Entity, error) {
+	where, ok := node.(query.Where)
+	if !ok {
+		return data.Entities(), nil
+	}
+	names := []string{}
+	for _, entity := range data.Entities() {
+		if strings.EqualFold(entity.Name, where.Entity) || strings.EqualFold(entity.Title, where.Entity) {
+			return []data.Entity{entity}, nil
+		}
+		names = append(names, entity.Name)
+	}
+	return nil, &query.ParseError{Query: q, Column: where.Column, Message: withSuggestions(fmt.Sprintf("unknown resource %s", where.Entity), suggestNames(names, where.Entity))}
+}
+
+//checkQueryFields returns a *query.ParseError for the first term whose field is not a field of any of the entities, with the closest field names
+func checkQueryFields(q string, node query.Node, entities []data.Entity, structMap map[string]map[string]data.Field) error {
+	for _, term := range query.Terms(node) {
+		if len(term.Field) == 0 {
+			continue
+		}
+		//The field of has: and missing: is their value
+		name := term.Field
+		if presenceOperators[strings.ToLower(term.Field)] {
+			name = term.Value
+		}
+		path := strings.Split(name, ".")
+		isKnown := false
+		for _, entity := range entities {
+			isKnown = isKnown || resolveField(entity, path, structMap)
+		}
+		if isKnown {
+			continue
+		}
+		for i := range path {
+			path[i] = data.FieldKey(path[i])
+		}
+		message := withSuggestions(fmt.Sprintf("unknown field %s", name), suggestNames(queryFieldNames(entities, structMap), strings.Join(path, ".")))
+		return &query.ParseError{Query: q, Column: term.Column, Message: message}
+	}
+	return nil
+}
+
+//resolveField returns true when the relations of the path lead from the entity to an entity with the last field of the path, ex. organization tags from tickets
+func resolveField(entity data.Entity, path []string, structMap map[string]map[string]data.Field) bool {
+	for _, name := range path[:len(path)-1] {
+		relation, ok := entity.Relation(name)
+		if !ok {
+			return false
+		}
+		entity, ok = data.LookupEntity(relation.Target)
+		if !ok {
+			return false
+		}
+	}
+	_, ok := structMap[entity.Name][data.FieldKey(path[len(path)-1])]
+	return ok
+}
+
+//queryFieldNames lists the fields of the entities and the fields of their linked entities, ex. status and organization.tags, for the suggestions
+func queryFieldNames(entities []data.Entity, structMap map[string]map[string]data.Field) (names []string) {
+	for _, entity := range entities {
+		names = append(names, sortedFieldKeys(structMap[entity.Name])...)
+		for _, relation := range entity.Relations {
+			for _, fieldKey := range sortedFieldKeys(structMap[relation.Target]) {
+				names = append(names, data.FieldKey(relation.Name)+"."+fieldKey)
+			}
+		}
+	}
+	return
+}
+
+func withSuggestions(message string, suggestions []string) string {
+	if len(suggestions) == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s, did you mean %s?", message, strings.Join(suggestions, ", "))
+}
+
+//entityEvaluator answers the terms of a query with the indexes of one entity
+type entityEvaluator struct {
+	query     string
+	entity    data.Entity
+	fieldMap  map[string]data.Field
+	structMap map[string]map[string]data.Field
+	all       []interface{}
+}
+
+//Term looks up the value in the field of the term, or in every field for a term without a field, the same way as the field specific and the direct value searches.
+//A field following a relation, ex. organization.tags, is looked up in the linked entity, then the records linked to the matched ones are found by the index of the relation field.
+//
+//has:field and missing:field match the records with a value for the field, or missing it in their data file.
+func (e *entityEvaluator) Term(term query.Term) (records []interface{}, err error) {
+	operator := strings.ToLower(term.Field)
+	if presenceOperators[operator] {
+		path := strings.Split(term.Value, ".")
+		if len(path) > 1 {
+			return e.linked(path[0], query.Term{Field: term.Field, Value: strings.Join(path[1:], "."), Column: term.Column})
+		}
+		field, ok := e.fieldMap[data.FieldKey(term.Value)]
+		if !ok {
+			return nil, query.ErrFieldAbsent
+		}
+		return presenceRecords(operator, field, e.All()), nil
+	}
+	path := term.Path()
+	if len(path) > 1 {
+		return e.linked(path[0], query.Term{Field: strings.Join(path[1:], "."), Value: term.Value, Column: term.Column})
+	}
+	if len(term.Field) == 0 {
+		isAdded := map[interface{}]bool{}
+		for _, fieldKey := range sortedFieldKeys(e.fieldMap) {
+			fieldRecords, err := lookupField(fieldKey, e.fieldMap[fieldKey], term.Value, false)
+			if err != nil {
+				continue
+			}
+			for _, record := range fieldRecords {
+				if !isAdded[record] {
+					isAdded[record] = true
+					records = append(records, record)
+				}
+			}
+		}
+		return
+	}
+	fieldKey := data.FieldKey(term.Field)
+	field, ok := e.fieldMap[fieldKey]
+	if !ok {
+		return nil, query.ErrFieldAbsent
+	}
+	set, isSet, err := parseValueSet(fieldKey, term.Value)
+	if err == nil && isSet {
+		records, err = lookupSet(fieldKey, field, set, e.All())
+	} else if err == nil {
+		records, err = lookupField(fieldKey, field, term.Value, true)
+	}
+	if err != nil {
+		return nil, &query.ParseError{Query: e.query, Column: term.Column, Message: fmt.Sprintf("invalid value for the %s field of %s: %v", term.Field, e.entity.Name, err)}
+	}
+	return
+}
+
+//linked evaluates the term against the entity linked by the relation, and returns the records linked to its matches
+func (e *entityEvaluator) linked(relationName string, term query.Term) ([]interface{}, error) {
+	relation, ok := e.entity.Relation(relationName)
+	if !ok {
+		return nil, query.ErrFieldAbsent
+	}
+	target, _ := data.LookupEntity(relation.Target)
+	targetEvaluator := &entityEvaluator{query: e.query, entity: target, fieldMap: e.structMap[target.Name], structMap: e.structMap}
+	targets, err := targetEvaluator.Term(term)
+	if err != nil {
+		return nil, err
+	}
+	return e.entity.LinkedTo(relation, targets, e.structMap), nil
+}
+
+//All returns the records of the entity in the order of their IDs, numeric IDs in ascending numbers
+func (e *entityEvaluator) All() []interface{} {
+	if e.all == nil {
+		e.all = allRecords(e.entity, e.fieldMap)
+	}
+	return e.all
+}
+
+//allRecords returns the records of the entity in the order of their IDs, numeric IDs in ascending numbers
+func allRecords(entity data.Entity, fieldMap map[string]data.Field) (records []interface{}) {
+	idField := fieldMap[entity.IDField]
+	if idField.IsRange() {
+		records, _ = idField.Range(data.Bound{}, data.Bound{})
+		return
+	}
+	for _, value := range sortedValues(idField.ValueMap) {
+		records = append(records, idField.ValueMap[value]...)
+	}
+	return
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"errors"
+	"regexp"
+	"searchDemo/src/data"
+	"strings"
+)
+
+var (
+	//betweenPattern matches "between 2016-04-01 and 2016-05-01"; both bounds are inclusive
+	betweenPattern = regexp.MustCompile(`(?i)^between\s+(.+?)\s+and\s+(.+)$`)
+	//comparisonPattern matches a single condition such as ">= 10" or "< now"
+	comparisonPattern = regexp.MustCompile(`^(>=|<=|>|<|=)\s*(.+)$`)
+	andPattern        = regexp.MustCompile(`(?i)\s+and\s+`)
+)
+
+//rangeCondition is one side or both sides of a range, ex. >= 10 is {lower: 10 inclusive}
+type rangeCondition struct {
+	lower data.Bound
+	upper data.Bound
+}
+
+//parseRange reads the range search value of a field, ex. ">= 10 and < 20", "between 2016-04-01 and 2016-05-01" or "< now".
+//Each condition may repeat the field name, ex. "submitter_id >= 10 AND submitter_id < 20". isRange is false when the value is not a range,
+//so it is searched as a plain value.
+func parseRange(fieldKey, value string) (conditions []rangeCondition, isRange bool, err error) {
+	trimmed := strings.TrimSpace(value)
+	match := betweenPattern.FindStringSubmatch(trimmed)
+	if match != nil {
+		lower, upper := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+		return []rangeCondition{{lower: data.Bound{Value: lower, Inclusive: true}, upper: data.Bound{Value: upper, Inclusive: true}}}, true, nil
+	}
+	for i, part := range andPattern.Split(trimmed, -1) {
+		part = trimFieldName(fieldKey, part)
+		match := comparisonPattern.FindStringSubmatch(part)
+		if match == nil {
+			if i == 0 {
+				return nil, false, nil
+			}
+			return nil, true, errors.New("invalid range, expected conditions such as >= 10 and < 20, or between 2016-04-01 and 2016-05-01")
+		}
+		operator, bound := match[1], strings.TrimSpace(match[2])
+		switch operator {
+		case ">":
+			conditions = append(conditions, rangeCondition{lower: data.Bound{Value: bound}})
+		case ">=":
+			conditions = append(conditions, rangeCondition{lower: data.Bound{Value: bound, Inclusive: true}})
+		case "<":
+			conditions = append(conditions, rangeCondition{upper: data.Bound{Value: bound}})
+		case "<=":
+			conditions = append(conditions, rangeCondition{upper: data.Bound{Value: bound, Inclusive: true}})
+		case "=":
+			conditions = append(conditions, rangeCondition{lower: data.Bound{Value: bound, Inclusive: true}, upper: data.Bound{Value: bound, Inclusive: true}})
+		}
+	}
+	return conditions, true, nil
+}
+
+//trimFieldName removes the searched field name in front of a condition; the name may be given as in the data file, ex. submitter_id
+func trimFieldName(fieldKey, condition string) string {
+	condition = strings.TrimSpace(condition)
+	end := strings.IndexAny(condition, "<>=")
+	if end <= 0 {
+		return condition
+	}
+	if data.FieldKey(strings.TrimSpace(condition[:end])) == fieldKey {
+		return condition[end:]
+	}
+	return condition
+}
+
+//searchRange returns the records matching every condition, in ascending order of the field value. Each condition is answered by the sorted index of the field.
+func searchRange(field data.Field, conditions []rangeCondition) (results []interface{}, err error) {
+	isMatched := map[interface{}]int{}
+	for i, condition := range conditions {
+		records, err := field.Range(condition.lower, condition.upper)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if isMatched[record] == i {
+				isMatched[record] = i + 1
+			}
+		}
+		if i == 0 {
+			results = records
+		}
+	}
+	matched := []interface{}{}
+	for _, record := range results {
+		if isMatched[record] == len(conditions) {
+			matched = append(matched, record)
+			//A list field can hold the record more than once
+			isMatched[record] = -1
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"fmt"
+	"searchDemo/src/data"
+	"strings"
+	"time"
+)
+
+//Reload func rebuilds the struct map from the data files and swaps it in. Searches already running keep the struct map they started with;
+//if the reload fails, the current struct map is kept. A reload asked by the user while the watch is reloading waits for it, then loads the data again.
+func (s *service) Reload() (err error) {
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+	s.lock.RLock()
+	previous := s.Sources
+	s.lock.RUnlock()
+	err = s.SetStructMap()
+	if err != nil {
+		return fmt.Errorf("reload data failed, keep searching the previous data: %v", err)
+	}
+	s.lock.RLock()
+	current := s.Sources
+	s.lock.RUnlock()
+	fmt.Println("Data reloaded:", describeChanges(previous, current))
+	return
+}
+
+//Watch func polls the size and modification time of the data files every interval, and reloads the data when any of them changed.
+//A change which fails to load is not retried until the files change again. It returns when stop is closed.
+func (s *service) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	s.lock.RLock()
+	lastSeen := s.Sources
+	s.lock.RUnlock()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		current, err := s.DataService.Fingerprints()
+		if err != nil || data.SameFingerprints(current, lastSeen) {
+			//A missing file is usually being replaced, so wait for the next poll
+			continue
+		}
+		lastSeen = current
+		err = s.Reload()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+//describeChanges lists the data files which differ between the two sets of fingerprints, for the reload log line
+func describeChanges(previous, current []data.SourceFingerprint) string {
+	previousByEntity := map[string]data.SourceFingerprint{}
+	for _, fingerprint := range previous {
+		previousByEntity[fingerprint.Entity] = fingerprint
+	}
+	changes := []string{}
+	for _, fingerprint := range current {
+		before, ok := previousByEntity[fingerprint.Entity]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s loaded from %s", fingerprint.Entity, fingerprint.Path))
+		case before.Path != fingerprint.Path:
+			changes = append(changes, fmt.Sprintf("%s moved from %s to %s", fingerprint.Entity, before.Path, fingerprint.Path))
+		case before.Size != fingerprint.Size:
+			changes = append(changes, fmt.Sprintf("%s changed size from %d to %d bytes", fingerprint.Path, before.Size, fingerprint.Size))
+		case before.ModTime != fingerprint.ModTime:
+			changes = append(changes, fmt.Sprintf("%s modified at %s", fingerprint.Path, time.Unix(0, fingerprint.ModTime).Format(time.RFC3339)))
+		}
+	}
+	if len(changes) == 0 {
+		return "no data file changed"
+	}
+	return strings.Join(changes, "; ")
+}
@@ -0,0 +1,37 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+)
+
+//ScoredResult is a result matched by a full-text field with its relevance score, shown as the first key of the result, ex. {"score":2.5,"_id":"t1",...}
+type ScoredResult struct {
+	Score  float64
+	Result interface{}
+}
+
+func (r ScoredResult) MarshalJSON() ([]byte, error) {
+	result, err := json.Marshal(r.Result)
+	if err != nil {
+		return nil, err
+	}
+	score, _ := json.Marshal(r.Score)
+	if len(result) < 2 || result[0] != '{' {
+		return json.Marshal(map[string]json.RawMessage{"score": score, "result": result})
+	}
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(`{"score":`)
+	buffer.Write(score)
+	if len(bytes.TrimSpace(result[1:len(result)-1])) != 0 {
+		buffer.WriteByte(',')
+	}
+	buffer.Write(result[1:])
+	return buffer.Bytes(), nil
+}
+
+//roundScore keeps three decimals of a score for the results
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
@@ -3,11 +3,14 @@ package search
 import (
 	"errors"
 	"fmt"
+	"os"
 	"searchDemo/src/data"
 	"searchDemo/src/interaction"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Service interface {
@@ -15,14 +18,28 @@ type Service interface {
 	SetStructMap() (err error)
 	RequestNewSearch() bool
 	GetStructMap() map[string]map[string]data.Field
+	GetValidationReport() *data.ValidationReport
+	Reload() (err error)
+	Watch(interval time.Duration, stop <-chan struct{})
+	Query(q string) (results interface{}, err error)
+	SearchField(structKey, fieldName, value string) (results interface{}, err error)
+	Page(cursor string) (results interface{}, err error)
+	RequestPage(page Page) (cursor string, isQuit bool)
 }
 
+//The struct map can be swapped by a reload while a search is running, so StructMap, ValidationReport and Sources are guarded by lock;
+//a search reads the struct map once through GetStructMap and keeps using that snapshot until it finishes.
+//reloadLock lets one reload run at a time, so a reload of older data never finishes after a newer one and replaces it.
 type service struct {
 	DataService        data.Service
 	InteractionService interaction.Service
 	StructMap          map[string]map[string]data.Field
 	SelectedStructKey  string
 	SelectedFieldKey   string
+	ValidationReport   *data.ValidationReport
+	Sources            []data.SourceFingerprint
+	lock               sync.RWMutex
+	reloadLock         sync.Mutex
 }
 
 func NewService(dataService data.Service, interactionService interaction.Service) Service {
@@ -31,7 +48,7 @@ func NewService(dataService data.Service, interactionService interaction.Service
 
 func (s *service) StartSearch() (results interface{}, isQuit bool, err error) {
 	fmt.Println("Welcome to Zendesk search. The search param is case insensitive. You can type 'quit' to leave the application")
-	fmt.Println("Select 1) for direct value search, or 2) for field specific search")
+	fmt.Println("Select 1) for direct value search, or 2) for field specific search, or 3) for query search, or type 'facets' to count the results of a query by field values, or 'reload' to reload the data files")
 	isQuit, input := s.InteractionService.GetUserInput()
 	if isQuit {
 		return
@@ -41,6 +58,12 @@ func (s *service) StartSearch() (results interface{}, isQuit bool, err error) {
 		return s.DirectSearchWithValue()
 	case "2":
 		return s.Search()
+	case "3":
+		return s.QuerySearch()
+	case "facets":
+		return s.FacetSearch()
+	case "reload":
+		err = s.Reload()
 	default:
 		err = errors.New("There is no available search type matched to your selection")
 	}
@@ -50,12 +73,13 @@ func (s *service) StartSearch() (results interface{}, isQuit bool, err error) {
 //Search func retrieves the user input and process the required search on the keywords given;
 //It returns results in string format if the search is successful; isQuit as true if user types 'quit' during the interaction; and error message if any error happens
 func (s *service) Search() (results interface{}, isQuit bool, err error) {
-	fmt.Println("Select 1) Tickets or 2) Users or 3) Organizations")
+	fmt.Println(entityMenu())
 	isQuit, searchStructParam := s.InteractionService.GetUserInput()
 	if isQuit {
 		return
 	}
-	fieldMap, err := s.setSearchStruct(searchStructParam)
+	structMap := s.GetStructMap()
+	fieldMap, err := s.setSearchStruct(structMap, searchStructParam)
 	if err != nil {
 		return
 	}
@@ -71,7 +95,7 @@ func (s *service) Search() (results interface{}, isQuit bool, err error) {
 	if isQuit {
 		return
 	}
-	typeName, err := s.setSearchFieldValue(searchFieldParam)
+	typeName, err := s.setSearchFieldValue(structMap, searchFieldParam)
 	if err != nil {
 		return
 	}
@@ -80,54 +104,207 @@ func (s *service) Search() (results interface{}, isQuit bool, err error) {
 	if typeName == "[]string" {
 		fmt.Println("You just need to type in a string and any slices contain your search value is treated as matched slices")
 	}
+	if typeName == "string" || typeName == "[]string" || typeName == "text" {
+		fmt.Println("You can also search a pattern, ex. fran* or *@flotonic.com, a regular expression between slashes, ex. /^fran.*a$/, or end the value with ~ to tolerate typos, ex. fransisca~")
+	}
+	if typeName == "text" {
+		fmt.Println("The field is searched by words, ex. type in korea to find every text containing Korea; common words such as the and of are ignored")
+	}
+	if typeName == "time" {
+		fmt.Println("Type in a timestamp such as 2016-04-28T11:19:34 -10:00; the same time in another time zone is also matched")
+	}
+	if typeName == "int" || typeName == "float64" || typeName == "time" {
+		fmt.Println("You can also search a range, ex. >= 10 and < 20, between 2016-04-01 and 2016-05-01, or < now for a time")
+	}
+	fmt.Println("Type in empty to find the records without a value for the field, in (a, b) to find any of several values, or != a and not in (a, b) to exclude values")
+	printClauseHelp()
 	isQuit, searchValueParam := s.InteractionService.GetUserInput()
 	if isQuit {
 		return
 	}
-	resultList, err := retrieveResults(s.SelectedStructKey, searchValueParam, []string{s.SelectedFieldKey}, s.StructMap)
-	if err != nil {
-		return
-	}
-	return resultList, false, nil
+	results, err = s.SearchField(s.SelectedStructKey, s.SelectedFieldKey, searchValueParam)
+	return
 }
 
 func (s *service) DirectSearchWithValue() (results interface{}, isQuit bool, err error) {
-	fmt.Println("Please enter the search value.")
+	fmt.Println("Please enter the search value. Type in field:value, ex. name:fran*, to only search the field, or field:empty, has:field and missing:field to search by the presence of a value")
+	printClauseHelp()
 	isQuit, value := s.InteractionService.GetUserInput()
 	if isQuit {
 		return
 	}
-	keyMap := map[string]string{
-		"1": "tickets",
-		"2": "users",
-		"3": "organizations",
-	}
+	results, err = s.searchValue(value)
+	return
+}
 
-	combinedResultsMap := map[string][]interface{}{}
+//searchValue searches the value in every field of every entity, or in the fields named by a field:value prefix, and returns the results keyed by entity.
+//The value may end with a sort clause and a page clause, ex. pending sort by priority limit 20.
+func (s *service) searchValue(input string) (results interface{}, err error) {
+	//has:field and missing:field are single term queries
+	if _, _, isPresence := parsePresence(input); isPresence {
+		return s.Query(input)
+	}
+	input, request, err := splitPage(input)
+	if err != nil {
+		return
+	}
+	if len(request.cursor) != 0 {
+		return s.Page(request.cursor)
+	}
+	value, sortKeys, err := splitSort(input)
+	if err != nil {
+		return
+	}
+	value, facetNames, isFaceted, err := splitFacets(value)
+	if err != nil {
+		return
+	}
+	value, paths, err := splitSelect(value)
+	if err != nil {
+		return
+	}
+	structMap := s.GetStructMap()
+	fieldMaps := []map[string]data.Field{}
+	for _, fieldMap := range structMap {
+		fieldMaps = append(fieldMaps, fieldMap)
+	}
+	err = checkSortFields(sortKeys, fieldMaps...)
+	if err != nil {
+		return
+	}
+	err = checkFacetFields(facetNames, fieldMaps...)
+	if err != nil {
+		return
+	}
+	err = checkSelectFields(paths, data.Entities()...)
+	if err != nil {
+		return
+	}
+	searchedFieldKey, value := splitFieldName(value, structMap)
+	entities := data.Entities()
+	//Each goroutine only writes the results of its own entity, so they are collected without a lock and keep the order of the fields
+	resultLists := make([][]interface{}, len(entities))
+	facetLists := make([][]Facet, len(entities))
 	var wg sync.WaitGroup
-	for structKey := range s.StructMap {
+	for i, entity := range entities {
 		wg.Add(1)
-		go func(structKey string) {
+		go func(i int, structKey string) {
 			defer wg.Done()
-			resultMapKey := keyMap[structKey]
 			fieldKeys := []string{}
-			for fieldKey := range s.StructMap[structKey] {
-				fieldKeys = append(fieldKeys, fieldKey)
+			for _, fieldKey := range sortedFieldKeys(structMap[structKey]) {
+				if len(searchedFieldKey) == 0 || fieldKey == searchedFieldKey {
+					fieldKeys = append(fieldKeys, fieldKey)
+				}
+			}
+			if len(fieldKeys) == 0 {
+				return
 			}
-			resultList, err := retrieveResults(structKey, value, fieldKeys, s.StructMap)
+			records, scores, err := retrieveResults(structKey, value, fieldKeys, sortKeys, structMap)
 			if err != nil {
 				//Omit the error in case other structs' retrieve results can return values;
 				return
 			}
-			combinedResultsMap[resultMapKey] = resultList
-		}(structKey)
+			if isFaceted {
+				facetLists[i] = countFacets(entities[i], records, facetNames, structMap[structKey])
+			}
+			resultLists[i], _ = processResults(structKey, records, scores, paths, structMap)
+		}(i, entity.Name)
 	}
 	wg.Wait()
+	combinedResultsMap := map[string][]interface{}{}
+	var facets map[string][]Facet
+	if isFaceted {
+		facets = map[string][]Facet{}
+	}
+	for i, resultList := range resultLists {
+		if resultList != nil {
+			combinedResultsMap[entities[i].Name] = resultList
+		}
+		if facetLists[i] != nil {
+			facets[entities[i].Name] = facetLists[i]
+		}
+	}
 	if len(combinedResultsMap) == 0 {
-		err = errors.New("No results returned")
+		fields := []data.Field{}
+		for _, entity := range data.Entities() {
+			for _, fieldKey := range sortedFieldKeys(structMap[entity.Name]) {
+				if len(searchedFieldKey) == 0 || fieldKey == searchedFieldKey {
+					fields = append(fields, structMap[entity.Name][fieldKey])
+				}
+			}
+		}
+		err = &NotFoundError{Message: "No results returned", Suggestions: suggestValues(fields, value)}
+		return
+	}
+	return s.paginate(cursorState{Path: pathValue, Value: input}, combinedResultsMap, facets, request), nil
+}
+
+//SearchField func runs a field specific search without the prompts, ex. SearchField("tickets", "status", "in (pending, hold)");
+//the field may be named as in the data file, ex. assignee_id, and the value accepts everything the prompt does. The results are a Page when the value ends with a page clause
+//or the results are paged by default.
+func (s *service) SearchField(structKey, fieldName, input string) (results interface{}, err error) {
+	structMap := s.GetStructMap()
+	structKey = strings.ToLower(structKey)
+	fieldMap, ok := structMap[structKey]
+	if !ok {
+		names := []string{}
+		for _, entity := range data.Entities() {
+			names = append(names, entity.Name)
+		}
+		err = &NotFoundError{Message: "No struct found", Suggestions: suggestNames(names, structKey)}
+		return
+	}
+	fieldKey := data.FieldKey(fieldName)
+	_, ok = fieldMap[fieldKey]
+	if !ok {
+		err = &NotFoundError{Message: "No field found", Suggestions: suggestNames(sortedFieldKeys(fieldMap), fieldKey)}
+		return
+	}
+	input, request, err := splitPage(input)
+	if err != nil {
+		return
+	}
+	if len(request.cursor) != 0 {
+		return s.Page(request.cursor)
+	}
+	value, sortKeys, err := splitSort(input)
+	if err != nil {
+		return
+	}
+	value, facetNames, isFaceted, err := splitFacets(value)
+	if err != nil {
+		return
+	}
+	value, paths, err := splitSelect(value)
+	if err != nil {
 		return
 	}
-	return combinedResultsMap, false, nil
+	err = checkSortFields(sortKeys, fieldMap)
+	if err != nil {
+		return
+	}
+	err = checkFacetFields(facetNames, fieldMap)
+	if err != nil {
+		return
+	}
+	entity, _ := data.LookupEntity(structKey)
+	err = checkSelectFields(paths, entity)
+	if err != nil {
+		return
+	}
+	records, scores, err := retrieveResults(structKey, value, []string{fieldKey}, sortKeys, structMap)
+	if err != nil {
+		return
+	}
+	var facets map[string][]Facet
+	if isFaceted {
+		facets = map[string][]Facet{structKey: countFacets(entity, records, facetNames, fieldMap)}
+	}
+	resultList, err := processResults(structKey, records, scores, paths, structMap)
+	if err != nil {
+		return
+	}
+	return s.paginate(cursorState{Path: pathField, Entity: structKey, Field: fieldKey, Value: input}, resultList, facets, request), nil
 }
 
 func (s *service) RequestNewSearch() bool {
@@ -139,59 +316,192 @@ func (s *service) RequestNewSearch() bool {
 	return true
 }
 
+//SetStructMap func loads the data files and builds the struct map for searching.
+//When an index snapshot is configured and the data files have not changed since it was saved, the struct map is read from the snapshot instead.
+//The new struct map replaces the current one at once, so it is also used to reload the data while searches are running.
 func (s *service) SetStructMap() (err error) {
-	tickets, users, organizations, err := s.DataService.LoadFile()
+	//Take the fingerprints before loading, so a file changed during the load is picked up again by the next watch poll
+	sources, _ := s.DataService.Fingerprints()
+	structMap, report, err := s.loadStructMap()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	//Keep the report even when strict validation fails, so the caller can show what is wrong with the data
+	s.ValidationReport = report
 	if err != nil {
 		return
 	}
-	structMap, err := s.DataService.PrepareStructMap(tickets, users, organizations)
+	s.StructMap = structMap
+	s.Sources = sources
+	return
+}
+
+func (s *service) loadStructMap() (structMap map[string]map[string]data.Field, report *data.ValidationReport, err error) {
+	structMap, report, err = s.DataService.LoadSnapshot()
 	if err == nil {
-		s.StructMap = structMap
+		return
+	}
+	if !errors.Is(err, data.ErrSnapshotDisabled) && !errors.Is(err, os.ErrNotExist) {
+		fmt.Println("Rebuilding the index:", err)
+	}
+
+	dataset, err := s.DataService.LoadFile()
+	if err != nil {
+		return
+	}
+	report, err = s.DataService.Validate(dataset)
+	if err != nil {
+		return
+	}
+	structMap, err = s.DataService.PrepareStructMap(dataset)
+	if err != nil {
+		return
+	}
+	//A failed snapshot only slows down the next start, so it does not fail the search
+	e := s.DataService.SaveSnapshot(structMap, report)
+	if e != nil && !errors.Is(e, data.ErrSnapshotDisabled) {
+		fmt.Println("Failed to save the index snapshot:", e)
 	}
 	return
 }
 
 func (s *service) GetStructMap() map[string]map[string]data.Field {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	return s.StructMap
 }
 
-func (s *service) setSearchStruct(param string) (fieldMap map[string]data.Field, err error) {
-	fieldMap, ok := s.StructMap[param]
+func (s *service) GetValidationReport() *data.ValidationReport {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ValidationReport
+}
+
+//unquoteValue returns the value between the quotes of a quoted search value, ex. "raise the limit 10", which is searched as it is written
+//instead of ending with a page clause; a value which is not quoted as a whole is returned unchanged
+func unquoteValue(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) >= 2 && strings.HasPrefix(trimmed, `"`) && strings.HasSuffix(trimmed, `"`) && strings.Count(trimmed, `"`) == 2 {
+		return trimmed[1 : len(trimmed)-1]
+	}
+	return value
+}
+
+//printClauseHelp prints the clauses which may end a search value, in the order they are given
+func printClauseHelp() {
+	fmt.Println("End the value with any of these clauses, in this order:")
+	fmt.Println("  select and the attributes to show, ex. pending select subject, assignee_name, organization.name")
+	fmt.Println("  facets= and the fields to count the results by, ex. pending facets=priority,via")
+	fmt.Println("  sort by and the fields to order the results, ex. pending sort by created_at desc, priority")
+	fmt.Println("  limit and offset to page the results, ex. pending limit 20 offset 40")
+}
+
+//sortedFieldKeys returns the field keys in alphabetical order, so the suggestions do not depend on the map order
+func sortedFieldKeys(fieldMap map[string]data.Field) []string {
+	keys := []string{}
+	for key := range fieldMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//sortedValues returns the values of a value map in alphabetical order
+func sortedValues(valueMap map[string][]interface{}) []string {
+	values := []string{}
+	for value := range valueMap {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+//entityMenu lists the registered entities for the struct selection, ex. Select 1) Tickets or 2) Users or 3) Organizations
+func entityMenu() string {
+	options := []string{}
+	for i, entity := range data.Entities() {
+		options = append(options, fmt.Sprintf("%d) %s", i+1, entity.Title))
+	}
+	return "Select " + strings.Join(options, " or ")
+}
+
+//setSearchStruct accepts either the menu number or the name of the entity
+func (s *service) setSearchStruct(structMap map[string]map[string]data.Field, param string) (fieldMap map[string]data.Field, err error) {
+	structKey := strings.ToLower(param)
+	entities := data.Entities()
+	index, e := strconv.Atoi(param)
+	if e == nil && index > 0 && index <= len(entities) {
+		structKey = entities[index-1].Name
+	}
+	fieldMap, ok := structMap[structKey]
 	if !ok {
-		err = errors.New("No struct found")
+		names := []string{}
+		for _, entity := range entities {
+			names = append(names, entity.Name)
+		}
+		err = &NotFoundError{Message: "No struct found", Suggestions: suggestNames(names, structKey)}
 		return
 	}
-	s.SelectedStructKey = param
+	s.SelectedStructKey = structKey
 	return
 }
 
-func (s *service) setSearchFieldValue(param string) (fieldType string, err error) {
+func (s *service) setSearchFieldValue(structMap map[string]map[string]data.Field, param string) (fieldType string, err error) {
 	paramLowerCase := strings.ToLower(param)
-	fieldMap, _ := s.StructMap[s.SelectedStructKey]
+	fieldMap, _ := structMap[s.SelectedStructKey]
 	field, ok := fieldMap[paramLowerCase]
 	if !ok {
-		err = errors.New("No field found")
+		err = &NotFoundError{Message: "No field found", Suggestions: suggestNames(sortedFieldKeys(fieldMap), paramLowerCase)}
 		return
 	}
 	s.SelectedFieldKey = paramLowerCase
 	return field.Type, nil
 }
 
-//Accepts multiple field keys query; it makes sure the returned results are not duplicated
-func retrieveResults(structKey, param string, fieldKeys []string, structMap map[string]map[string]data.Field) (results []interface{}, err error) {
-	paramLowerCase := strings.ToLower(param)
+//Accepts multiple field keys query; it makes sure the returned results are not duplicated.
+//The results matched by full-text fields are ranked by their BM25 score, summed over the matched fields with the field boosts, and the best match is returned first;
+//the results are ordered by the sort fields first when there are any, and by ID when they are tied. The matched records are returned with their scores for processResults.
+func retrieveResults(structKey, param string, fieldKeys []string, sortKeys []sortKey, structMap map[string]map[string]data.Field) (records []interface{}, scores map[interface{}]float64, err error) {
 	fieldMap, _ := structMap[structKey]
+	param = unquoteValue(param)
 	accumulatedResultsList := []interface{}{}
 	//This map's key expects to be the pointer of a struct. By checking whether the struct pointer exists, it avoids the duplicated pointers stored into the results list.
 	//Thus accumulatedResultsList only gets the results which does not exist in the map appended.
 	resultsMap := map[interface{}]bool{}
+	scores = map[interface{}]float64{}
+	//Patterns, fuzzy values and sets are not the words of the texts, so their matches are not ranked
+	_, isPattern, _ := parsePattern(param)
+	_, _, isFuzzy := parseFuzzy(param)
+	//A field specific search also accepts a set of values, ex. in (pending, hold) or != low
+	set, isSet := valueSet{}, false
+	if len(fieldKeys) == 1 {
+		set, isSet, err = parseValueSet(fieldKeys[0], param)
+		if err != nil {
+			err = fmt.Errorf("Invalid search value for field %s: %v", fieldKeys[0], err)
+			return
+		}
+	}
 	for _, fieldKey := range fieldKeys {
 		field, _ := fieldMap[fieldKey]
-		resultsList, ok := field.ValueMap[paramLowerCase]
-		if !ok {
+		var resultsList []interface{}
+		var e error
+		if isSet {
+			entity, _ := data.LookupEntity(structKey)
+			resultsList, e = lookupSet(fieldKey, field, set, allRecords(entity, fieldMap))
+		} else {
+			resultsList, e = lookupField(fieldKey, field, param, len(fieldKeys) == 1)
+		}
+		if e != nil {
+			//A value of another type is an error for a field specific search, while a search on all fields skips the fields it does not fit
+			if len(fieldKeys) == 1 {
+				err = fmt.Errorf("Invalid search value for field %s: %v", fieldKey, e)
+				return
+			}
 			continue
 		}
 		for _, result := range resultsList {
+			if !isPattern && !isFuzzy && !isSet {
+				scores[result] += field.Score(param, result)
+			}
 			isExist, _ := resultsMap[result]
 			if !isExist {
 				resultsMap[result] = true
@@ -201,148 +511,73 @@ func retrieveResults(structKey, param string, fieldKeys []string, structMap map[
 	}
 
 	if len(accumulatedResultsList) == 0 {
-		err = errors.New("No results found")
-		return
-	}
-	return processResults(accumulatedResultsList, structMap)
-}
-
-func processResults(resultsList []interface{}, structMap map[string]map[string]data.Field) (processedResults []interface{}, err error) {
-
-	switch resultsList[0].(type) {
-	case *data.Ticket:
-		return processTicketResults(resultsList, structMap)
-	case *data.User:
-		return processUserResults(resultsList, structMap)
-	case *data.Organization:
-		return processOrganizationResults(resultsList, structMap)
-	}
-	err = errors.New("No matched type for process")
-	return
-}
-
-func processTicketResults(resultsList []interface{}, structMap map[string]map[string]data.Field) (processedResults []interface{}, err error) {
-	//Skip to check map contains the key here as if the struct map is not complete, the processData step should have already reported errors.
-	userMap, _ := structMap["2"]
-	organizationMap, _ := structMap["3"]
-	ticketsForDisplay := []data.TicketForDisplay{}
-
-	for _, result := range resultsList {
-		ticket := result.(*data.Ticket)
-
-		assignee := getLinkedUser(strconv.Itoa(ticket.AssigneeID), userMap["id"])
-
-		submitter := getLinkedUser(strconv.Itoa(ticket.SubmitterID), userMap["id"])
-
-		orgList := getLinkedStructs(strconv.Itoa(ticket.OrganizationID), organizationMap["id"])
-		org := &data.Organization{}
-		if len(orgList) > 0 {
-			org = orgList[0].(*data.Organization)
+		fields := []data.Field{}
+		for _, fieldKey := range fieldKeys {
+			fields = append(fields, fieldMap[fieldKey])
 		}
-
-		//Create a ticketForDisplay struct which contains the ticket information, and the linked struct values
-		ticketForDisplay := data.TicketForDisplay{Ticket: *ticket, AssigneeName: assignee.Name, SubmitterName: submitter.Name, OrganizationName: org.Name}
-		ticketsForDisplay = append(ticketsForDisplay, ticketForDisplay)
-	}
-	if len(ticketsForDisplay) == 0 {
-		err = errors.New("No tickets are available in the search")
+		err = &NotFoundError{Message: "No results found", Suggestions: suggestValues(fields, param)}
 		return
 	}
-	for _, ticket := range ticketsForDisplay {
-		processedResults = append(processedResults, ticket)
-	}
-	return
+	entity, _ := data.LookupEntity(structKey)
+	sortResults(entity, accumulatedResultsList, scores, sortKeys, fieldMap)
+	return accumulatedResultsList, scores, nil
 }
 
-func processUserResults(resultsList []interface{}, structMap map[string]map[string]data.Field) (processedResults []interface{}, err error) {
-	ticketMap, _ := structMap["1"]
-	organizationMap, _ := structMap["3"]
-	usersForDisplay := []data.UserForDisplay{}
-	for _, result := range resultsList {
-
-		assignedTicketIDs := []string{}
-		submittedTicketIDs := []string{}
-		user := result.(*data.User)
-
-		//Find linked struct to the user, such as assigned tickets, submitted tickets and organization.
-		orgList := getLinkedStructs(strconv.Itoa(user.OrganizationID), organizationMap["id"])
-		org := &data.Organization{}
-		if len(orgList) > 0 {
-			org = orgList[0].(*data.Organization)
+//lookupField searches the field for the value; a fuzzy value, a wildcard or a regular expression is matched against the string, list and text fields,
+//and a field specific search also accepts a range on the int, float and time fields, or empty for the records without a value
+func lookupField(fieldKey string, field data.Field, value string, isFieldSearch bool) ([]interface{}, error) {
+	//empty finds the records without a value for the field, blank or missing from the data file
+	if isFieldSearch && data.IsEmptyKeyword(value) {
+		return field.Empty(), nil
+	}
+	if field.IsFuzzy() {
+		term, distance, isFuzzy := parseFuzzy(value)
+		if isFuzzy {
+			return field.Fuzzy(term, distance)
 		}
-		ticketList := getLinkedStructs(strconv.Itoa(user.ID), ticketMap["assigneeid"])
-
-		for _, t := range ticketList {
-			ticket := t.(*data.Ticket)
-			assignedTicketIDs = append(assignedTicketIDs, ticket.ID)
+	}
+	if field.IsPattern() {
+		pattern, isPattern, err := parsePattern(value)
+		if err != nil {
+			return nil, err
 		}
-
-		ticketList = getLinkedStructs(strconv.Itoa(user.ID), ticketMap["submitterid"])
-
-		for _, t := range ticketList {
-			ticket := t.(*data.Ticket)
-			submittedTicketIDs = append(submittedTicketIDs, ticket.ID)
+		if isPattern {
+			return field.Match(pattern)
 		}
-
-		userForDisplay := data.UserForDisplay{User: *user, OrganizationName: org.Name, SubmittedTicketIDs: submittedTicketIDs, AssignedTicketsIDs: assignedTicketIDs}
-		usersForDisplay = append(usersForDisplay, userForDisplay)
 	}
-	if len(usersForDisplay) == 0 {
-		err = errors.New("No users are available in the search")
-		return
+	if !isFieldSearch || !field.IsRange() {
+		return field.Lookup(value)
+	}
+	conditions, isRange, err := parseRange(fieldKey, value)
+	if err != nil {
+		return nil, err
 	}
-	for _, user := range usersForDisplay {
-		processedResults = append(processedResults, user)
+	if !isRange {
+		return field.Lookup(value)
 	}
-	return
+	return searchRange(field, conditions)
 }
 
-func processOrganizationResults(resultsList []interface{}, structMap map[string]map[string]data.Field) (processedResults []interface{}, err error) {
-	ticketMap, _ := structMap["1"]
-	userMap, _ := structMap["2"]
-	orgsForDisplay := []data.OrganizationForDisplay{}
+//processResults turns the matched records into the display values of their entity, with the linked records of every relation;
+//the displays are projected down to the selected paths when there are any, and the results with a relevance score are shown with it
+func processResults(structKey string, resultsList []interface{}, scores map[interface{}]float64, paths []string, structMap map[string]map[string]data.Field) (processedResults []interface{}, err error) {
+	entity, ok := data.LookupEntity(structKey)
+	if !ok {
+		err = errors.New("No matched type for process")
+		return
+	}
 	for _, result := range resultsList {
-		userNames := []string{}
-		ticketIDs := []string{}
-		organization := result.(*data.Organization)
-
-		//Find linked struct to the organization, such as all users and tickets with matched organization id.
-		ticketList := getLinkedStructs(strconv.Itoa(organization.ID), ticketMap["organizationid"])
-		for _, t := range ticketList {
-			ticket := t.(*data.Ticket)
-			ticketIDs = append(ticketIDs, ticket.ID)
+		display := result
+		if entity.Display != nil {
+			display = entity.Display(result, entity.Linked(result, structMap))
 		}
-
-		userList := getLinkedStructs(strconv.Itoa(organization.ID), userMap["organizationid"])
-		for _, t := range userList {
-			user := t.(*data.User)
-			userNames = append(userNames, user.Name)
+		if len(paths) != 0 {
+			display = entity.Project(result, display, paths, structMap)
 		}
-		orgForDisplay := data.OrganizationForDisplay{Organization: *organization, TicketIDs: ticketIDs, UserNames: userNames}
-		orgsForDisplay = append(orgsForDisplay, orgForDisplay)
-	}
-	if len(orgsForDisplay) == 0 {
-		err = errors.New("No organizations are available in the search")
-		return
-	}
-	for _, org := range orgsForDisplay {
-		processedResults = append(processedResults, org)
-	}
-	return
-}
-
-func getLinkedStructs(value string, linkedField data.Field) (linkedStructs []interface{}) {
-	//If there is no available key in the value map, return an empty linkedStructs back; this means the searched struct has no linked structs on the requested field
-	linkedStructs, _ = linkedField.ValueMap[value]
-	return
-}
-
-func getLinkedUser(value string, userField data.Field) (user *data.User) {
-	ulist := getLinkedStructs(value, userField)
-	if len(ulist) > 0 {
-		user = ulist[0].(*data.User)
-	} else {
-		user = &data.User{}
+		if scores[result] > 0 {
+			display = ScoredResult{Score: roundScore(scores[result]), Result: display}
+		}
+		processedResults = append(processedResults, display)
 	}
 	return
 }
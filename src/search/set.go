@@ -0,0 +1,112 @@
+package search
+
+import (
+	"errors"
+	"regexp"
+	"searchDemo/src/data"
+	"strings"
+)
+
+var (
+	//inPattern matches "in (pending, hold)" and "not in (low, normal)", optionally after the field name, ex. status in (pending, hold)
+	inPattern = regexp.MustCompile(`(?i)^(?:(\S+)\s+)??(not\s+)?in\s*\((.*)\)$`)
+	//notEqualPattern matches "!= low", optionally after the field name, ex. priority != low
+	notEqualPattern = regexp.MustCompile(`^(?:([^\s!]+)\s*)??!=\s*(.+)$`)
+)
+
+//valueSet is the values of a field specific search matching any of them, ex. in (pending, hold).
+//A negated set, ex. != low or not in (low, normal), matches the records with a value for the field which is none of them.
+type valueSet struct {
+	values    []string
+	isNegated bool
+}
+
+//splitSetField reads a set search value into the field name in front of it, empty when there is none, and the set, ex. status in (pending, hold)
+func splitSetField(value string) (fieldName, set string, isSet bool) {
+	trimmed := strings.TrimSpace(value)
+	match := inPattern.FindStringSubmatch(trimmed)
+	if match != nil {
+		return match[1], strings.TrimSpace(trimmed[len(match[1]):]), true
+	}
+	match = notEqualPattern.FindStringSubmatch(trimmed)
+	if match != nil {
+		return match[1], strings.TrimSpace(trimmed[len(match[1]):]), true
+	}
+	return
+}
+
+//parseValueSet reads the set search value of a field. The field name may be repeated in front of the set, ex. status in (pending, hold);
+//isSet is false when the value is not a set or names another field, so it is searched as a plain value.
+func parseValueSet(fieldKey, value string) (set valueSet, isSet bool, err error) {
+	fieldName, setValue, isSet := splitSetField(value)
+	if !isSet || (len(fieldName) != 0 && data.FieldKey(fieldName) != fieldKey) {
+		return valueSet{}, false, nil
+	}
+	match := inPattern.FindStringSubmatch(setValue)
+	if match != nil {
+		set = valueSet{values: splitList(match[3]), isNegated: len(match[2]) != 0}
+	} else {
+		match = notEqualPattern.FindStringSubmatch(setValue)
+		set = valueSet{values: []string{strings.Trim(strings.TrimSpace(match[2]), `"`)}, isNegated: true}
+	}
+	if len(set.values) == 0 {
+		err = errors.New("the list of values is empty, expected values such as in (pending, hold)")
+	}
+	return set, true, err
+}
+
+//splitList splits the values of an in list on the commas; a quoted value may contain commas, ex. in ("a, b", c)
+func splitList(list string) (values []string) {
+	value := strings.Builder{}
+	isQuoted := false
+	addValue := func() {
+		trimmed := strings.TrimSpace(value.String())
+		if len(trimmed) != 0 {
+			values = append(values, trimmed)
+		}
+		value.Reset()
+	}
+	for _, r := range list {
+		switch {
+		case r == '"':
+			isQuoted = !isQuoted
+		case r == ',' && !isQuoted:
+			addValue()
+		default:
+			value.WriteRune(r)
+		}
+	}
+	addValue()
+	return
+}
+
+//lookupSet unites the records of every value of the set, each value searched like a field specific search value.
+//A negated set keeps the other records of all instead, without the records missing the field, as they have no value to compare.
+func lookupSet(fieldKey string, field data.Field, set valueSet, all []interface{}) (records []interface{}, err error) {
+	isMatched := map[interface{}]bool{}
+	for _, value := range set.values {
+		valueRecords, err := lookupField(fieldKey, field, value, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range valueRecords {
+			if !isMatched[record] {
+				isMatched[record] = true
+				records = append(records, record)
+			}
+		}
+	}
+	if !set.isNegated {
+		return
+	}
+	for _, record := range field.Missing {
+		isMatched[record] = true
+	}
+	records = nil
+	for _, record := range all {
+		if !isMatched[record] {
+			records = append(records, record)
+		}
+	}
+	return
+}
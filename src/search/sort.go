@@ -0,0 +1,138 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"searchDemo/src/data"
+	"sort"
+	"strings"
+)
+
+//sortPattern finds the sort clause ending a search value, ex. pending sort by created_at desc, priority
+var sortPattern = regexp.MustCompile(`(?i)(?:^|\s)(sort\s+by)(?:\s|$)`)
+
+//sortKey is a field of a sort clause, in ascending order unless isDescending; name is the field as it was typed, for the errors
+type sortKey struct {
+	fieldKey     string
+	name         string
+	isDescending bool
+}
+
+//splitSort reads the sort clause at the end of a search value, ex. pending sort by created_at desc, priority. The last sort by outside quotes starts the clause,
+//so a quoted value may contain the words; value is the search value without the clause.
+func splitSort(input string) (value string, keys []sortKey, err error) {
+	start, end := sortClause(input)
+	if start < 0 {
+		return input, nil, nil
+	}
+	for _, part := range strings.Split(input[end:], ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+		key := sortKey{fieldKey: data.FieldKey(words[0]), name: words[0]}
+		direction := ""
+		if len(words) == 2 {
+			direction = strings.ToLower(words[1])
+		}
+		if len(words) > 2 || (len(words) == 2 && direction != "asc" && direction != "desc") {
+			return "", nil, fmt.Errorf("invalid sort field %q, expected a field name followed by asc or desc", strings.TrimSpace(part))
+		}
+		key.isDescending = direction == "desc"
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return "", nil, errors.New("expected fields after sort by, ex. sort by created_at desc, priority")
+	}
+	return strings.TrimSpace(input[:start]), keys, nil
+}
+
+//sortClause returns the byte offsets of the sort keyword and of the first field after sort by, or -1 when there is no sort clause
+func sortClause(input string) (start, end int) {
+	start, end = -1, -1
+	for _, match := range sortPattern.FindAllStringSubmatchIndex(input, -1) {
+		if strings.Count(input[:match[2]], `"`)%2 == 0 {
+			start, end = match[2], match[1]
+		}
+	}
+	return
+}
+
+//checkSortFields returns a NotFoundError with the closest field names for the first sort field which is not a field of any of the field maps
+func checkSortFields(keys []sortKey, fieldMaps ...map[string]data.Field) error {
+	for _, key := range keys {
+		names := []string{}
+		isKnown := false
+		for _, fieldMap := range fieldMaps {
+			_, ok := fieldMap[key.fieldKey]
+			isKnown = isKnown || ok
+			names = append(names, sortedFieldKeys(fieldMap)...)
+		}
+		if !isKnown {
+			return &NotFoundError{Message: fmt.Sprintf("No sort field %s found", key.name), Suggestions: suggestNames(names, key.fieldKey)}
+		}
+	}
+	return nil
+}
+
+//sortResults orders the results of an entity by the sort fields, with the records without a value for a field last in both directions.
+//The results which are still tied are ordered by descending relevance score, then by ID, so the same search always lists its results in the same order.
+//The sort keys of every result are computed once, so a timestamp is not parsed again each time its record is compared.
+func sortResults(entity data.Entity, results []interface{}, scores map[interface{}]float64, keys []sortKey, fieldMap map[string]data.Field) {
+	position := map[interface{}]int{}
+	for i, record := range allRecords(entity, fieldMap) {
+		position[record] = i
+	}
+	fields := []data.Field{}
+	isDescending := []bool{}
+	for _, key := range keys {
+		field, ok := fieldMap[key.fieldKey]
+		if !ok {
+			//The field belongs to another entity of a direct value search
+			continue
+		}
+		fields = append(fields, field)
+		isDescending = append(isDescending, key.isDescending)
+	}
+	type sortedResult struct {
+		record   interface{}
+		hasValue []bool
+		values   []data.SortKey
+	}
+	sorted := make([]sortedResult, len(results))
+	for i, record := range results {
+		sorted[i] = sortedResult{record: record, hasValue: make([]bool, len(fields)), values: make([]data.SortKey, len(fields))}
+		for k, field := range fields {
+			sorted[i].hasValue[k] = field.HasValue(record)
+			if sorted[i].hasValue[k] {
+				sorted[i].values[k] = field.SortKey(record)
+			}
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		for k, field := range fields {
+			if a.hasValue[k] != b.hasValue[k] {
+				return a.hasValue[k]
+			}
+			if !a.hasValue[k] {
+				continue
+			}
+			c := field.CompareKeys(a.values[k], b.values[k])
+			if isDescending[k] {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		if scores[a.record] != scores[b.record] {
+			return scores[a.record] > scores[b.record]
+		}
+		return position[a.record] < position[b.record]
+	})
+	for i := range sorted {
+		results[i] = sorted[i].record
+	}
+}
@@ -0,0 +1,105 @@
+package search_test
+
+import (
+	"encoding/json"
+	"os"
+	"searchDemo/src/config"
+	"searchDemo/src/data"
+	"searchDemo/src/search"
+	"testing"
+)
+
+func TestFacets(t *testing.T) {
+	testCases := map[string]struct {
+		search               func(s search.Service) (interface{}, error)
+		expectedFacets       string
+		expectedErrorMessage string
+	}{
+		"field specific search with named facets": {
+			search: func(s search.Service) (interface{}, error) {
+				return s.SearchField("tickets", "priority", "high facets=status, tags")
+			},
+			expectedFacets: `{"tickets":[{"field":"status","counts":[{"value":"pending","count":2}]},` +
+				`{"field":"tags","counts":[{"value":"Tag1.1","count":1},{"value":"Tag1.2","count":1},{"value":"Tag2.1","count":1},{"value":"Tag2.2","count":1}]}]}`,
+		},
+		"query with the default facets of the entity": {
+			search: func(s search.Service) (interface{}, error) {
+				return s.Query("tickets where _id:t2 facets")
+			},
+			expectedFacets: `{"tickets":[{"field":"status","counts":[{"value":"pending","count":1}]},{"field":"priority","counts":[{"value":"high","count":1}]},` +
+				`{"field":"type","counts":[{"value":"incident","count":1}]},{"field":"via","counts":[{"value":"web","count":1}]},` +
+				`{"field":"tags","counts":[{"value":"Tag2.1","count":1},{"value":"Tag2.2","count":1}]}]}`,
+		},
+		"direct value search with facets of several entities": {
+			search: func(s search.Service) (interface{}, error) {
+				s = search.NewService(&mockDataServiceForSearch{}, &mockInteractionServiceForSearch{userInputs: []string{"1", "test facets=tags"}, t: t})
+				s.SetStructMap()
+				results, _, err := s.StartSearch()
+				return results, err
+			},
+			expectedFacets: `{"organizations":[{"field":"tags","counts":[{"value":"otag1.1","count":1},{"value":"otag1.2","count":1}]}],` +
+				`"tickets":[{"field":"tags","counts":[{"value":"Tag1.1","count":1},{"value":"Tag1.2","count":1}]}],` +
+				`"users":[{"field":"tags","counts":[{"value":"utag1.1","count":1},{"value":"utag1.2","count":1},{"value":"utag2.1","count":1},{"value":"utag2.2","count":1}]}]}`,
+		},
+		"facet on a text field": {
+			search: func(s search.Service) (interface{}, error) {
+				return s.Query("_id:t1 OR _id:t2 facets=description")
+			},
+			expectedErrorMessage: "facet field description is a text field, expected a field with a few distinct values such as status or tags",
+		},
+		"unknown facet field": {
+			search: func(s search.Service) (interface{}, error) {
+				return s.SearchField("tickets", "priority", "high facets=stauts")
+			},
+			expectedErrorMessage: "No facet field stauts found. Did you mean: status?",
+		},
+	}
+	for tc, tp := range testCases {
+		s := search.NewService(&mockDataServiceForSearch{}, nil)
+		s.SetStructMap()
+		results, err := tp.search(s)
+		if len(tp.expectedErrorMessage) != 0 {
+			if err == nil || err.Error() != tp.expectedErrorMessage {
+				t.Errorf("For test case <%s>, Expected error message is <%s> but Actual error is <%v>", tc, tp.expectedErrorMessage, err)
+			}
+			continue
+		}
+		page, ok := results.(search.Page)
+		if err != nil || !ok {
+			t.Errorf("For test case <%s>, Expected a page of results with facets, but Actual results are <%v> and error is <%v>", tc, results, err)
+			continue
+		}
+		ab, _ := json.Marshal(page.Facets)
+		if string(ab) != tp.expectedFacets {
+			t.Errorf("For test case <%s>, Expected facets are <%s>, but Actually are <%s>", tc, tp.expectedFacets, string(ab))
+		}
+	}
+}
+
+func TestFacetsWithTiedCounts(t *testing.T) {
+	dir := writeDataFiles(t, map[string]string{
+		"tickets": `[{"_id": "t1", "organization_id": 101, "created_at": "2016-04-28T11:19:34 -10:00", "status": "pending"},` +
+			`{"_id": "t2", "organization_id": 2, "created_at": "2016-04-28T20:00:00 -00:00", "status": "pending"},` +
+			`{"_id": "t3", "organization_id": 11, "created_at": "2016-04-29T01:00:00 +10:00", "status": "pending"}]`,
+		"users":         `[{"_id": 1, "name": "Francisca"}]`,
+		"organizations": `[{"_id": 101, "name": "Enthaze"}]`,
+	})
+	defer os.RemoveAll(dir)
+	s := search.NewService(data.NewService(data.NewStreamSerializer(), config.Config{DataDir: dir}), nil)
+	err := s.SetStructMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := s.SearchField("tickets", "status", "pending facets=organization_id,created_at")
+	page, ok := results.(search.Page)
+	if err != nil || !ok {
+		t.Fatalf("Expected a page of results with facets, but Actual results are <%v> and error is <%v>", results, err)
+	}
+	//Numbers are tied by value and timestamps chronologically, instead of as strings
+	expectedFacets := `{"tickets":[{"field":"organizationid","counts":[{"value":"2","count":1},{"value":"11","count":1},{"value":"101","count":1}]},` +
+		`{"field":"createdat","counts":[{"value":"2016-04-29T01:00:00 +10:00","count":1},{"value":"2016-04-28T20:00:00 -00:00","count":1},{"value":"2016-04-28T11:19:34 -10:00","count":1}]}]}`
+	ab, _ := json.Marshal(page.Facets)
+	if string(ab) != expectedFacets {
+		t.Errorf("Expected facets are <%s>, but Actually are <%s>", expectedFacets, string(ab))
+	}
+}
@@ -0,0 +1,195 @@
+package search_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"searchDemo/src/config"
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"searchDemo/src/search"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	tickets := []data.TicketForDisplay{
+		data.TicketForDisplay{
+			Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+		},
+		data.TicketForDisplay{
+			Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+		},
+	}
+	users := []data.UserForDisplay{
+		data.UserForDisplay{
+			User: *mock.MockUsers[0], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[0].ID}, AssignedTicketsIDs: []string{mock.MockTickets[1].ID},
+		},
+		data.UserForDisplay{
+			User: *mock.MockUsers[1], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[1].ID}, AssignedTicketsIDs: []string{mock.MockTickets[0].ID},
+		},
+	}
+	organization := data.OrganizationForDisplay{
+		Organization: *mock.MockOrganizations[0], UserNames: []string{mock.MockUsers[0].Name, mock.MockUsers[1].Name}, TicketIDs: []string{mock.MockTickets[0].ID, mock.MockTickets[1].ID},
+	}
+	testCases := map[string]struct {
+		userInputs          []string
+		expectedTotal       int
+		expectedResults     interface{}
+		expectedNextResults interface{}
+	}{
+		"direct value search pages across the entities": {
+			userInputs:          []string{"1", "1 limit 3"},
+			expectedTotal:       5,
+			expectedResults:     map[string]interface{}{"tickets": tickets, "users": users[:1]},
+			expectedNextResults: map[string]interface{}{"users": users[1:], "organizations": []data.OrganizationForDisplay{organization}},
+		},
+		"field specific search with a sort clause": {
+			userInputs:          []string{"2", "1", "priority", "high sort by submitter_id desc limit 1"},
+			expectedTotal:       2,
+			expectedResults:     tickets[1:],
+			expectedNextResults: tickets[:1],
+		},
+		"query search": {
+			userInputs:          []string{"3", "_id:(t1 OR t2) LIMIT 1"},
+			expectedTotal:       2,
+			expectedResults:     map[string]interface{}{"tickets": tickets[:1]},
+			expectedNextResults: map[string]interface{}{"tickets": tickets[1:]},
+		},
+	}
+	for tc, tp := range testCases {
+		s := search.NewService(&mockDataServiceForSearch{}, &mockInteractionServiceForSearch{userInputs: tp.userInputs, testCase: tc, t: t})
+		s.SetStructMap()
+		results, _, err := s.StartSearch()
+		page, ok := results.(search.Page)
+		if err != nil || !ok {
+			t.Errorf("For test case <%s>, Expected a page of results, but Actual results are <%v> and error is <%v>", tc, results, err)
+			continue
+		}
+		if page.Total != tp.expectedTotal || len(page.PreviousCursor) != 0 || len(page.NextCursor) == 0 {
+			t.Errorf("For test case <%s>, Expected the first page of <%d> results with a next cursor only, but Actual page is <%+v>", tc, tp.expectedTotal, page)
+		}
+		eb, _ := json.Marshal(tp.expectedResults)
+		ab, _ := json.Marshal(page.Results)
+		if string(eb) != string(ab) {
+			t.Errorf("For test case <%s>, Expected results are <%s>, but Actually are <%s>", tc, string(eb), string(ab))
+		}
+
+		results, err = s.Page(page.NextCursor)
+		nextPage, ok := results.(search.Page)
+		if err != nil || !ok {
+			t.Errorf("For test case <%s>, Expected the next page of results, but Actual results are <%v> and error is <%v>", tc, results, err)
+			continue
+		}
+		if len(nextPage.NextCursor) != 0 || len(nextPage.PreviousCursor) == 0 {
+			t.Errorf("For test case <%s>, Expected the last page with a previous cursor only, but Actual page is <%+v>", tc, nextPage)
+		}
+		eb, _ = json.Marshal(tp.expectedNextResults)
+		ab, _ = json.Marshal(nextPage.Results)
+		if string(eb) != string(ab) {
+			t.Errorf("For test case <%s>, Expected next results are <%s>, but Actually are <%s>", tc, string(eb), string(ab))
+		}
+
+		results, err = s.Page(nextPage.PreviousCursor)
+		ab, _ = json.Marshal(results)
+		pb, _ := json.Marshal(page)
+		if err != nil || string(ab) != string(pb) {
+			t.Errorf("For test case <%s>, Expected the previous cursor returns the first page <%s>, but Actual page is <%s> and error is <%v>", tc, string(pb), string(ab), err)
+		}
+	}
+}
+
+func TestPageWithInvalidCursor(t *testing.T) {
+	testCases := map[string]struct {
+		cursor               string
+		expectedErrorMessage string
+	}{
+		"cursor is not issued by a search": {
+			cursor:               "abc$",
+			expectedErrorMessage: "invalid cursor, expected a next_cursor or previous_cursor printed with the results of a search",
+		},
+		"cursor is issued on other data files": {
+			cursor:               base64.RawURLEncoding.EncodeToString([]byte(`{"p":"value","v":"1","o":2,"l":2,"d":"1ra0dk"}`)),
+			expectedErrorMessage: "the cursor has expired because the data files changed, run the search again",
+		},
+	}
+	for tc, tp := range testCases {
+		s := search.NewService(&mockDataServiceForSearch{}, nil)
+		s.SetStructMap()
+		_, err := s.Page(tp.cursor)
+		if err == nil || err.Error() != tp.expectedErrorMessage {
+			t.Errorf("For test case <%s>, Expected error message is <%s> but Actual error is <%v>", tc, tp.expectedErrorMessage, err)
+		}
+	}
+}
+
+func TestPageClauseInSearchValue(t *testing.T) {
+	dir := writeDataFiles(t, map[string]string{
+		"tickets":       `[{"_id": "t1", "external_id": "raise the limit 10", "subject": "Raise the limit 10"}, {"_id": "t2", "external_id": "raise the", "subject": "Raise the"}]`,
+		"users":         `[{"_id": 1, "name": "Francisca"}]`,
+		"organizations": `[{"_id": 101, "name": "Enthaze"}]`,
+	})
+	defer os.RemoveAll(dir)
+	s := search.NewService(data.NewService(data.NewStreamSerializer(), config.Config{DataDir: dir}), nil)
+	err := s.SetStructMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]struct {
+		search          func() (interface{}, error)
+		expectedResults string
+	}{
+		"value ending with a limit clause is paged": {
+			search: func() (interface{}, error) {
+				return s.SearchField("tickets", "external_id", "raise the limit 10")
+			},
+			expectedResults: `{"limit":10,"offset":0,"results":[{"_id":"t2"}],"total":1}`,
+		},
+		"quoted value ending with limit and a number is searched as it is written": {
+			search: func() (interface{}, error) {
+				return s.SearchField("tickets", "external_id", `"raise the limit 10"`)
+			},
+			expectedResults: `[{"_id":"t1"}]`,
+		},
+		"quoted value in a query is searched as it is written": {
+			search: func() (interface{}, error) {
+				return s.Query(`subject:"raise the limit 10"`)
+			},
+			expectedResults: `{"tickets":[{"_id":"t1"}]}`,
+		},
+		"limit without a value in front of it is searched as it is written": {
+			search: func() (interface{}, error) {
+				return s.SearchField("tickets", "subject", "limit 10")
+			},
+			expectedResults: `[{"_id":"t1"}]`,
+		},
+	}
+	for tc, tp := range testCases {
+		results, err := tp.search()
+		//Only the ids are compared
+		ab, _ := json.Marshal(results)
+		var ids interface{}
+		json.Unmarshal(ab, &ids)
+		ab, _ = json.Marshal(keepIDs(ids))
+		if err != nil || string(ab) != tp.expectedResults {
+			t.Errorf("For test case <%s>, Expected results are <%s>, but Actual results are <%s> and error is <%v>", tc, tp.expectedResults, string(ab), err)
+		}
+	}
+}
+
+//keepIDs removes every attribute of the results but their _id
+func keepIDs(v interface{}) interface{} {
+	switch r := v.(type) {
+	case []interface{}:
+		for i := range r {
+			r[i] = keepIDs(r[i])
+		}
+	case map[string]interface{}:
+		if id, ok := r["_id"]; ok {
+			return map[string]interface{}{"_id": id}
+		}
+		for key := range r {
+			r[key] = keepIDs(r[key])
+		}
+	}
+	return v
+}
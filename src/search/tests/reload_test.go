@@ -0,0 +1,136 @@
+package search_test
+
+import (
+	"searchDemo/src/data"
+	"searchDemo/src/search"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	testCases := map[string]struct {
+		isReloadReturnError   bool
+		expectedHasError      bool
+		expectedStructMapName string
+	}{
+		"Fail to reload the changed data files": {
+			isReloadReturnError:   true,
+			expectedHasError:      true,
+			expectedStructMapName: "old",
+		},
+		"Successfully reload the changed data files": {
+			expectedHasError:      false,
+			expectedStructMapName: "new",
+		},
+	}
+	for tc, tp := range testCases {
+		mockDataService := &mockDataServiceForReload{version: "old"}
+		s := search.NewService(mockDataService, nil)
+		err := s.SetStructMap()
+		if err != nil {
+			t.Fatalf("For test case <%s>, Expected the initial load succeeds, but Actually got error <%v>", tc, err)
+		}
+		runningSearchStructMap := s.GetStructMap()
+
+		mockDataService.setVersion("new")
+		mockDataService.isLoadFileReturnError = tp.isReloadReturnError
+		err = s.Reload()
+		if (err != nil) != tp.expectedHasError {
+			t.Errorf("For test case <%s>, Expected has error is <%v>, but Actual error is <%v>", tc, tp.expectedHasError, err)
+		}
+		if _, ok := s.GetStructMap()[tp.expectedStructMapName]; !ok {
+			t.Errorf("For test case <%s>, Expected struct map after reload is <%s>, but Actually is <%v>", tc, tp.expectedStructMapName, s.GetStructMap())
+		}
+		if _, ok := runningSearchStructMap["old"]; !ok || len(runningSearchStructMap) != 1 {
+			t.Errorf("For test case <%s>, Expected the struct map used by a running search is not changed by the reload, but Actually is <%v>", tc, runningSearchStructMap)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	mockDataService := &mockDataServiceForReload{version: "old"}
+	s := search.NewService(mockDataService, nil)
+	err := s.SetStructMap()
+	if err != nil {
+		t.Fatalf("Expected the initial load succeeds, but Actually got error <%v>", err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.Watch(time.Millisecond, stop)
+
+	mockDataService.setVersion("new")
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := s.GetStructMap()["new"]; ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("Expected the struct map is reloaded after the data files changed, but Actually is <%v>", s.GetStructMap())
+}
+
+func TestConcurrentReloads(t *testing.T) {
+	mockDataService := &mockDataServiceForReload{version: "old", loadTime: 5 * time.Millisecond}
+	s := search.NewService(mockDataService, nil)
+	err := s.SetStructMap()
+	if err != nil {
+		t.Fatalf("Expected the initial load succeeds, but Actually got error <%v>", err)
+	}
+	mockDataService.setVersion("new")
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Reload()
+		}()
+	}
+	wg.Wait()
+	if mockDataService.maxLoading != 1 {
+		t.Errorf("Expected the reloads run one at a time, but Actually %d of them loaded the data at once", mockDataService.maxLoading)
+	}
+}
+
+//mockDataServiceForReload builds a struct map keyed by the current version of the data, and reports the version as the data file fingerprint.
+//Building the struct map takes loadTime, and maxLoading counts the most struct maps built at once.
+type mockDataServiceForReload struct {
+	mockDataService
+	version    string
+	loadTime   time.Duration
+	loading    int
+	maxLoading int
+	lock       sync.Mutex
+}
+
+func (s *mockDataServiceForReload) setVersion(version string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.version = version
+}
+
+func (s *mockDataServiceForReload) currentVersion() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.version
+}
+
+func (s *mockDataServiceForReload) PrepareStructMap(dataset data.Dataset) (map[string]map[string]data.Field, error) {
+	s.lock.Lock()
+	s.loading++
+	if s.loading > s.maxLoading {
+		s.maxLoading = s.loading
+	}
+	s.lock.Unlock()
+	time.Sleep(s.loadTime)
+	s.lock.Lock()
+	s.loading--
+	s.lock.Unlock()
+	return map[string]map[string]data.Field{
+		s.currentVersion(): map[string]data.Field{},
+	}, nil
+}
+
+func (s *mockDataServiceForReload) Fingerprints() ([]data.SourceFingerprint, error) {
+	return []data.SourceFingerprint{{Entity: "tickets", Path: s.currentVersion()}}, nil
+}
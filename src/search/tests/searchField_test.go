@@ -0,0 +1,66 @@
+package search_test
+
+import (
+	"encoding/json"
+	"searchDemo/src/data"
+	"searchDemo/src/mock"
+	"searchDemo/src/search"
+	"testing"
+)
+
+func TestSearchField(t *testing.T) {
+	testCases := map[string]struct {
+		structKey            string
+		fieldName            string
+		value                string
+		expectedResults      []data.TicketForDisplay
+		expectedErrorMessage string
+	}{
+		"field named as in the data file with a list of values": {
+			structKey: "Tickets",
+			fieldName: "assignee_id",
+			value:     "in (2, 3)",
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"excluded value": {
+			structKey: "tickets",
+			fieldName: "assigneeid",
+			value:     "!= 2",
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"unknown field": {
+			structKey:            "tickets",
+			fieldName:            "asignee_id",
+			value:                "1",
+			expectedErrorMessage: "No field found. Did you mean: assigneeid?",
+		},
+	}
+	for tc, tp := range testCases {
+		s := search.NewService(&mockDataServiceForSearch{}, nil)
+		s.SetStructMap()
+		results, err := s.SearchField(tp.structKey, tp.fieldName, tp.value)
+		if len(tp.expectedErrorMessage) != 0 {
+			if err == nil || err.Error() != tp.expectedErrorMessage {
+				t.Errorf("For test case <%s>, Expected error message is <%s> but Actual error is <%v>", tc, tp.expectedErrorMessage, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("For test case <%s>, Expected there is no error returned, but Actual error is <%v>", tc, err)
+			continue
+		}
+		eb, _ := json.Marshal(tp.expectedResults)
+		ab, _ := json.Marshal(results)
+		if string(eb) != string(ab) {
+			t.Errorf("For test case <%s>, Expected results are <%s>, but Actually are <%s>", tc, string(eb), string(ab))
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package search_test
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"searchDemo/src/config"
 	"searchDemo/src/data"
 	"searchDemo/src/search"
 	"testing"
@@ -10,6 +15,8 @@ import (
 func TestSetStructMap(t *testing.T) {
 	testCases := map[string]struct {
 		isLoadFileReturnError            bool
+		isValidateReturnError            bool
+		hasSnapshot                      bool
 		isPrepareStructMapReturnError    bool
 		expectedIsPrepareStructMapCalled bool
 		expectedHasError                 bool
@@ -21,6 +28,12 @@ func TestSetStructMap(t *testing.T) {
 			expectedHasError:                 true,
 			expectedErrorMessage:             "error load file",
 		},
+		"Fail to validate the data in strict mode": {
+			isValidateReturnError:            true,
+			expectedIsPrepareStructMapCalled: false,
+			expectedHasError:                 true,
+			expectedErrorMessage:             "error validate the data",
+		},
 		"Fail to prepare the struct map": {
 			expectedIsPrepareStructMapCalled: true,
 			isPrepareStructMapReturnError:    true,
@@ -31,9 +44,14 @@ func TestSetStructMap(t *testing.T) {
 			expectedIsPrepareStructMapCalled: true,
 			expectedHasError:                 false,
 		},
+		"Successfully load the struct map from the snapshot": {
+			hasSnapshot:                      true,
+			expectedIsPrepareStructMapCalled: false,
+			expectedHasError:                 false,
+		},
 	}
 	for tc, tp := range testCases {
-		mockDataService := &mockDataService{isLoadFileReturnError: tp.isLoadFileReturnError, isPrepareStructMapReturnError: tp.isPrepareStructMapReturnError}
+		mockDataService := &mockDataService{isLoadFileReturnError: tp.isLoadFileReturnError, isPrepareStructMapReturnError: tp.isPrepareStructMapReturnError, isValidateReturnError: tp.isValidateReturnError, hasSnapshot: tp.hasSnapshot}
 		s := search.NewService(mockDataService, nil)
 		err := s.SetStructMap()
 		if err != nil {
@@ -43,6 +61,9 @@ func TestSetStructMap(t *testing.T) {
 			if err.Error() != tp.expectedErrorMessage {
 				t.Errorf("For test case <%s>, Expected error message is <%s>, but Actual message is <%s>", tc, tp.expectedErrorMessage, err.Error())
 			}
+			if tp.isValidateReturnError && !s.GetValidationReport().HasFailures() {
+				t.Errorf("For test case <%s>, Expected the validation report is kept after the strict validation failed, but Actually not", tc)
+			}
 			if mockDataService.IsPrepareStructMapCalled != tp.expectedIsPrepareStructMapCalled {
 				t.Errorf("For test case <%s>, Expected dataServie PrepareStructMap func called is <%v>, but Actually is <%v>", tc, tp.expectedIsPrepareStructMapCalled, !tp.expectedIsPrepareStructMapCalled)
 			}
@@ -51,30 +72,125 @@ func TestSetStructMap(t *testing.T) {
 			if len(savedStructMap) == 0 {
 				t.Errorf("For test case <%s>, Expected struct map is saved, but Actually not", tc)
 			}
+			if mockDataService.IsPrepareStructMapCalled != tp.expectedIsPrepareStructMapCalled {
+				t.Errorf("For test case <%s>, Expected dataServie PrepareStructMap func called is <%v>, but Actually is <%v>", tc, tp.expectedIsPrepareStructMapCalled, !tp.expectedIsPrepareStructMapCalled)
+			}
+			if mockDataService.IsSaveSnapshotCalled != tp.expectedIsPrepareStructMapCalled {
+				t.Errorf("For test case <%s>, Expected the snapshot is saved only after the struct map is rebuilt, but dataService SaveSnapshot func called is <%v>", tc, mockDataService.IsSaveSnapshotCalled)
+			}
+		}
+	}
+}
+
+func TestSetStructMapWithSnapshotOfLenientRun(t *testing.T) {
+	dir := writeDataFiles(t, map[string]string{
+		"tickets":       `[{"_id": "436bf9b0-1147-4c0a-8439-6f79833bff5b", "type": "outage", "status": "pending", "submitter_id": 1}]`,
+		"users":         `[{"_id": 1, "name": "Francisca"}]`,
+		"organizations": `[{"_id": 101, "name": "Enthaze"}]`,
+	})
+	defer os.RemoveAll(dir)
+	snapshotFile := filepath.Join(dir, "index.snapshot")
+	var err error
+	for _, mode := range []string{data.ValidationLenient, data.ValidationStrict} {
+		dataService := data.NewService(data.NewStreamSerializer(), config.Config{DataDir: dir, SnapshotFile: snapshotFile, ValidationMode: mode})
+		err = search.NewService(dataService, nil).SetStructMap()
+		if mode == data.ValidationLenient && err != nil {
+			t.Fatalf("Expected the lenient run loads the data and saves the snapshot, but Actual error is <%v>", err)
 		}
 	}
+	if err == nil {
+		t.Errorf("Expected the strict run fails on the invalid ticket type even with the snapshot of the lenient run, but Actually not")
+	}
+}
+
+func TestSortAfterSnapshot(t *testing.T) {
+	dir := writeDataFiles(t, map[string]string{
+		"tickets":       `[{"_id": "t0", "status": "pending"}, {"_id": "t1", "status": "pending", "assignee_id": 2}, {"_id": "t2", "status": "pending", "assignee_id": 1}]`,
+		"users":         `[{"_id": 1, "name": "Francisca"}, {"_id": 2, "name": "Cross"}]`,
+		"organizations": `[{"_id": 101, "name": "Enthaze"}]`,
+	})
+	defer os.RemoveAll(dir)
+	dataService := data.NewService(data.NewStreamSerializer(), config.Config{DataDir: dir, SnapshotFile: filepath.Join(dir, "index.snapshot")})
+	//The first run builds the struct map and saves the snapshot, the second one loads it back
+	for _, run := range []string{"built", "snapshot"} {
+		s := search.NewService(dataService, nil)
+		err := s.SetStructMap()
+		if err != nil {
+			t.Fatalf("For the %s struct map, Expected there is no error, but Actual error is <%v>", run, err)
+		}
+		results, err := s.SearchField("tickets", "status", "pending select _id sort by assignee_id")
+		ab, _ := json.Marshal(results)
+		//The ticket without an assignee is listed last
+		expectedResults := `[{"_id":"t2"},{"_id":"t1"},{"_id":"t0"}]`
+		if err != nil || string(ab) != expectedResults {
+			t.Errorf("For the %s struct map, Expected results are <%s>, but Actual results are <%s> and error is <%v>", run, expectedResults, string(ab), err)
+		}
+	}
+}
+
+//writeDataFiles writes the content of each data file, keyed by its entity, to a new temporary data directory
+func writeDataFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for label, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, label+".json"), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
 }
 
 type mockDataService struct {
 	isLoadFileReturnError         bool
 	IsPrepareStructMapCalled      bool
 	isPrepareStructMapReturnError bool
+	isValidateReturnError         bool
+	hasSnapshot                   bool
+	IsSaveSnapshotCalled          bool
 }
 
-func (s *mockDataService) LoadFile() (tickets []*data.Ticket, users []*data.User, organizations []*data.Organization, err error) {
+func (s *mockDataService) LoadFile() (dataset data.Dataset, err error) {
 	if s.isLoadFileReturnError {
 		err = errors.New("error load file")
 	}
 	return
 }
 
-func (s *mockDataService) PrepareStructMap(tickets []*data.Ticket, users []*data.User, organizations []*data.Organization) (map[string]map[string]data.Field, error) {
+func (s *mockDataService) PrepareStructMap(dataset data.Dataset) (map[string]map[string]data.Field, error) {
 	s.IsPrepareStructMapCalled = true
 	if s.isPrepareStructMapReturnError {
 		err := errors.New("error prepare the struct map")
 		return nil, err
 	}
 	return map[string]map[string]data.Field{
-		"1": map[string]data.Field{},
+		"tickets": map[string]data.Field{},
 	}, nil
 }
+
+func (s *mockDataService) Validate(dataset data.Dataset) (*data.ValidationReport, error) {
+	if s.isValidateReturnError {
+		return &data.ValidationReport{Failures: []data.RuleFailure{{Entity: "tickets"}}}, errors.New("error validate the data")
+	}
+	return nil, nil
+}
+
+func (s *mockDataService) LoadSnapshot() (map[string]map[string]data.Field, *data.ValidationReport, error) {
+	if s.hasSnapshot {
+		return map[string]map[string]data.Field{
+			"tickets": map[string]data.Field{},
+		}, nil, nil
+	}
+	return nil, nil, data.ErrSnapshotStale
+}
+
+func (s *mockDataService) SaveSnapshot(structMap map[string]map[string]data.Field, report *data.ValidationReport) error {
+	s.IsSaveSnapshotCalled = true
+	return nil
+}
+
+func (s *mockDataService) Fingerprints() ([]data.SourceFingerprint, error) {
+	return nil, nil
+}
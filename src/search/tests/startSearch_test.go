@@ -29,6 +29,11 @@ func TestStartSearch(t *testing.T) {
 			userInputs:     []string{"1", "quit"},
 			expectedIsQuit: true,
 		},
+		"user input '1' for search type, then type a name with typos": {
+			userInputs:           []string{"1", "tets tesb"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No results returned. Did you mean: test, testb, test1, test2, testa?",
+		},
 		"user input '1' for search type, then type invalid search value": {
 			userInputs:           []string{"1", "abcd"},
 			expectedHasError:     true,
@@ -70,6 +75,109 @@ func TestStartSearch(t *testing.T) {
 				},
 			},
 		},
+		"user input '1' for search type, then type 'name:testa*' for search value": {
+			userInputs: []string{"1", "name:testa*"},
+			expectedResults: map[string]interface{}{
+				"users": []data.UserForDisplay{
+					data.UserForDisplay{
+						User: *mock.MockUsers[0], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[0].ID}, AssignedTicketsIDs: []string{mock.MockTickets[1].ID},
+					},
+				},
+			},
+		},
+		"user input '3' for search type, then type a query": {
+			userInputs: []string{"3", "status:pending AND (_id:t1 OR _id:t2) AND NOT submitter_id:1"},
+			expectedResults: map[string]interface{}{
+				"tickets": []data.TicketForDisplay{
+					data.TicketForDisplay{
+						Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+					},
+				},
+			},
+		},
+		"user input '3' for search type, then type a NOT query on a field of one resource": {
+			userInputs:      []string{"3", "NOT submitter_id:1 select _id"},
+			expectedResults: json.RawMessage(`{"tickets":[{"_id":"t2"}]}`),
+		},
+		"user input '3' for search type, then type an AND of NOT terms on fields of one resource": {
+			userInputs:      []string{"3", "NOT submitter_id:1 AND NOT priority:low select _id"},
+			expectedResults: json.RawMessage(`{"tickets":[{"_id":"t2"}]}`),
+		},
+		"user input '3' for search type, then type an AND of NOT terms on fields no resource has together": {
+			userInputs:           []string{"3", "NOT submitter_id:1 AND NOT name:testb"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No results returned",
+		},
+		"user input '3' for search type, then type a query with a field group": {
+			userInputs: []string{"3", "_id:(1 OR 2) AND NOT name:testb"},
+			expectedResults: map[string]interface{}{
+				"users": []data.UserForDisplay{
+					data.UserForDisplay{
+						User: *mock.MockUsers[0], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[0].ID}, AssignedTicketsIDs: []string{mock.MockTickets[1].ID},
+					},
+				},
+				"organizations": []data.OrganizationForDisplay{
+					data.OrganizationForDisplay{
+						Organization: *mock.MockOrganizations[0], UserNames: []string{mock.MockUsers[0].Name, mock.MockUsers[1].Name}, TicketIDs: []string{mock.MockTickets[0].ID, mock.MockTickets[1].ID},
+					},
+				},
+			},
+		},
+		"user input '3' for search type, then type a query across related resources": {
+			userInputs: []string{"3", "tickets where organization.tags:otag1.1 and submitter.name:testb"},
+			expectedResults: map[string]interface{}{
+				"tickets": []data.TicketForDisplay{
+					data.TicketForDisplay{
+						Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+					},
+				},
+			},
+		},
+		"user input '3' for search type, then type a query with an unknown resource": {
+			userInputs:           []string{"3", "tikets where status:pending"},
+			expectedHasError:     true,
+			expectedErrorMessage: "query error at column 1: unknown resource tikets, did you mean tickets?",
+		},
+		"user input '3' for search type, then type a query with an unknown relation": {
+			userInputs:           []string{"3", "tickets where organisation.name:test"},
+			expectedHasError:     true,
+			expectedErrorMessage: "query error at column 15: unknown field organisation.name, did you mean organization.name?",
+		},
+		"user input '1' for search type, then type field:empty": {
+			userInputs: []string{"1", "description:empty"},
+			expectedResults: map[string]interface{}{
+				"tickets": []interface{}{
+					data.TicketForDisplay{
+						Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+					},
+				},
+			},
+		},
+		"user input '1' for search type, then type has:field with a typo": {
+			userInputs:           []string{"1", "has:signatur"},
+			expectedHasError:     true,
+			expectedErrorMessage: "query error at column 1: unknown field signatur, did you mean signature?",
+		},
+		"user input '3' for search type, then type a query with has:field": {
+			userInputs: []string{"3", "users where has:signature and organization.details:details1"},
+			expectedResults: map[string]interface{}{
+				"users": []data.UserForDisplay{
+					data.UserForDisplay{
+						User: *mock.MockUsers[1], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[1].ID}, AssignedTicketsIDs: []string{mock.MockTickets[0].ID},
+					},
+				},
+			},
+		},
+		"user input '3' for search type, then type a query with a syntax error": {
+			userInputs:           []string{"3", "status:pending AND (priority:high"},
+			expectedHasError:     true,
+			expectedErrorMessage: "query error at column 34: expected ) to close the ( at column 20, but found the end of the query",
+		},
+		"user input '3' for search type, then type a query with an unknown field": {
+			userInputs:           []string{"3", "status:pending AND priorty:high"},
+			expectedHasError:     true,
+			expectedErrorMessage: "query error at column 20: unknown field priorty, did you mean priority?",
+		},
 		"user input '2' for search type, then type invalid input for struct type selection": {
 			userInputs:           []string{"2", "4"},
 			expectedHasError:     true,
@@ -93,6 +201,157 @@ func TestStartSearch(t *testing.T) {
 			expectedHasError:     true,
 			expectedErrorMessage: "No results found",
 		},
+		"user input '2' for search type, then type '2', then type 'id', then type a value which is not an int": {
+			userInputs:           []string{"2", "2", "id", "abc"},
+			expectedHasError:     true,
+			expectedErrorMessage: `Invalid search value for field id: "abc" is not a valid int`,
+		},
+		"user input '2' for search type, then type '1', then type 'submitterid', then type a range repeating the field name": {
+			userInputs: []string{"2", "1", "submitterid", "submitter_id >= 1 AND submitter_id < 2"},
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"user input '2' for search type, then type '1', then type 'submitterid', then type a list of values": {
+			userInputs: []string{"2", "1", "submitterid", "in (1, 2)"},
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"user input '2' for search type, then type '1', then type 'submitterid', then type values to exclude": {
+			userInputs: []string{"2", "1", "submitterid", "submitter_id not in (1)"},
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"user input '2' for search type, then type '1', then type 'submitterid', then type an empty list": {
+			userInputs:           []string{"2", "1", "submitterid", "in ()"},
+			expectedHasError:     true,
+			expectedErrorMessage: "Invalid search value for field submitterid: the list of values is empty, expected values such as in (pending, hold)",
+		},
+		"user input '1' for search type, then type a value to exclude from a field": {
+			userInputs: []string{"1", "submitter_id != 1"},
+			expectedResults: map[string]interface{}{
+				"tickets": []data.TicketForDisplay{
+					data.TicketForDisplay{
+						Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+					},
+				},
+			},
+		},
+		"user input '2' for search type, then type '1', then type 'priority', then type a value with a sort clause": {
+			userInputs: []string{"2", "1", "priority", "high sort by submitter_id desc"},
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"user input '1' for search type, then type a value sorted by an unknown field": {
+			userInputs:           []string{"1", "pending sort by prioirty"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No sort field prioirty found. Did you mean: priority?",
+		},
+		"user input '3' for search type, then type a query with a sort clause": {
+			userInputs: []string{"3", "_id:(t1 OR t2) sort by priority, created_at desc"},
+			expectedResults: map[string]interface{}{
+				"tickets": []data.TicketForDisplay{
+					data.TicketForDisplay{
+						Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+					},
+					data.TicketForDisplay{
+						Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+					},
+				},
+			},
+		},
+		"user input '3' for search type, then type a query with an invalid sort clause": {
+			userInputs:           []string{"3", "status:pending sort by priority up"},
+			expectedHasError:     true,
+			expectedErrorMessage: `query error at column 16: invalid sort field "priority up", expected a field name followed by asc or desc`,
+		},
+		"user input '1' for search type, then type a value with an invalid limit": {
+			userInputs:           []string{"1", "pending limit 0"},
+			expectedHasError:     true,
+			expectedErrorMessage: `invalid limit "0", expected a positive number of results`,
+		},
+		"user input '2' for search type, then type '1', then type 'status', then type a cursor after a value": {
+			userInputs:           []string{"2", "1", "status", "pending cursor eyJwIjoidmFsdWUi"},
+			expectedHasError:     true,
+			expectedErrorMessage: "a cursor continues the search it was printed with, type it alone, ex. cursor eyJwIjoidmFsdWUi...",
+		},
+		"user input '3' for search type, then type a query with an invalid offset": {
+			userInputs:           []string{"3", "status:pending limit 5 offset -1"},
+			expectedHasError:     true,
+			expectedErrorMessage: `query error at column 16: invalid offset "-1", expected the number of results to skip`,
+		},
+		"user input '2' for search type, then type '1', then type 'priority', then type a value with a select clause": {
+			userInputs:      []string{"2", "1", "priority", "high select _id, assignee_name, assignee.email, organization.name"},
+			expectedResults: json.RawMessage(`[{"_id":"t1","assignee_name":"Test TestB","assignee.email":"user2@test.com","organization.name":"test org1"},{"_id":"t2","assignee_name":"Test TestA","assignee.email":"user1@test.com","organization.name":"test org1"}]`),
+		},
+		"user input '3' for search type, then type a query selecting the attributes of related records": {
+			userInputs:      []string{"3", "users where _id:1 select name, submitted_tickets._id"},
+			expectedResults: json.RawMessage(`{"users":[{"name":"Test TestA","submitted_tickets._id":["t1"]}]}`),
+		},
+		"user input '1' for search type, then type a value selecting an unknown attribute": {
+			userInputs:           []string{"1", "t1 select subjet"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No select field subjet found. Did you mean: subject?",
+		},
+		"user input '3' for search type, then type a query with an invalid select clause": {
+			userInputs:           []string{"3", "status:pending select name email"},
+			expectedHasError:     true,
+			expectedErrorMessage: `query error at column 16: invalid select field "name email", expected attribute names or dotted paths separated by commas, ex. select subject, assignee.email`,
+		},
+		"user input 'facets' for search type, then type a query to count": {
+			userInputs: []string{"facets", "status:pending"},
+		},
+		"user input 'facets' for search type, then type a query with an unknown facet field": {
+			userInputs:           []string{"facets", "status:pending facets=prioirty"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No facet field prioirty found. Did you mean: priority?",
+		},
+		"user input '2' for search type, then type '1', then type 'dueat', then type a range between two dates": {
+			userInputs: []string{"2", "1", "dueat", "between 2019-05-13 and 2019-05-13T11:00:01"},
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"user input '2' for search type, then type '1', then type 'dueat', then type '< now'": {
+			userInputs: []string{"2", "1", "dueat", "< now"},
+			expectedResults: []data.TicketForDisplay{
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+				data.TicketForDisplay{
+					Ticket: *mock.MockTickets[1], SubmitterName: mock.MockUsers[1].Name, AssigneeName: mock.MockUsers[0].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				},
+			},
+		},
+		"user input '2' for search type, then type '2', then type 'id', then type an invalid range": {
+			userInputs:           []string{"2", "2", "id", "> 1 and abc"},
+			expectedHasError:     true,
+			expectedErrorMessage: "Invalid search value for field id: invalid range, expected conditions such as >= 10 and < 20, or between 2016-04-01 and 2016-05-01",
+		},
+		"user input '2' for search type, then type '2', then type 'id', then type a range which is not an int": {
+			userInputs:           []string{"2", "2", "id", ">= one"},
+			expectedHasError:     true,
+			expectedErrorMessage: `Invalid search value for field id: "one" is not a valid int`,
+		},
 		"user input '2' for search type, then type '1', then type 'id', then type 'quit'": {
 			userInputs:     []string{"2", "1", "id", "quit"},
 			expectedIsQuit: true,
@@ -124,6 +383,48 @@ func TestStartSearch(t *testing.T) {
 				},
 			},
 		},
+		"user input '2' for search type, then type '1', then type 'description', then type 'Description'": {
+			userInputs: []string{"2", "1", "description", "Description"},
+			expectedResults: []search.ScoredResult{
+				search.ScoredResult{Score: 0.492, Result: data.TicketForDisplay{
+					Ticket: *mock.MockTickets[0], SubmitterName: mock.MockUsers[0].Name, AssigneeName: mock.MockUsers[1].Name, OrganizationName: mock.MockOrganizations[0].Name,
+				}},
+			},
+		},
+		"user input '2' for search type, then type '2', then type 'email', then type '*@TEST.com'": {
+			userInputs: []string{"2", "2", "email", "*@TEST.com"},
+			expectedResults: []data.UserForDisplay{
+				data.UserForDisplay{
+					User: *mock.MockUsers[0], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[0].ID}, AssignedTicketsIDs: []string{mock.MockTickets[1].ID},
+				},
+				data.UserForDisplay{
+					User: *mock.MockUsers[1], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[1].ID}, AssignedTicketsIDs: []string{mock.MockTickets[0].ID},
+				},
+			},
+		},
+		"user input '2' for search type, then type '2', then type 'email', then type an invalid regular expression": {
+			userInputs:           []string{"2", "2", "email", "/user[/"},
+			expectedHasError:     true,
+			expectedErrorMessage: "Invalid search value for field email: invalid regular expression /user[/: error parsing regexp: missing closing ]: `[`",
+		},
+		"user input '2' for search type, then type '2', then type 'email', then type a value with a typo and '~1'": {
+			userInputs: []string{"2", "2", "email", "user1@tset.com~1"},
+			expectedResults: []data.UserForDisplay{
+				data.UserForDisplay{
+					User: *mock.MockUsers[0], OrganizationName: mock.MockOrganizations[0].Name, SubmittedTicketIDs: []string{mock.MockTickets[0].ID}, AssignedTicketsIDs: []string{mock.MockTickets[1].ID},
+				},
+			},
+		},
+		"user input '2' for search type, then type '2', then type 'email', then type a value with a typo": {
+			userInputs:           []string{"2", "2", "email", "user1@tset.com"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No results found. Did you mean: user1@test.com, user2@test.com?",
+		},
+		"user input '2' for search type, then type '1', then type a field name with a typo": {
+			userInputs:           []string{"2", "1", "submiterid"},
+			expectedHasError:     true,
+			expectedErrorMessage: "No field found. Did you mean: submitterid?",
+		},
 		"user input '2' for search type, then type '2', then type 'active', then type 'true'": {
 			userInputs: []string{"2", "2", "active", "true"},
 			expectedResults: []data.UserForDisplay{
@@ -135,6 +436,14 @@ func TestStartSearch(t *testing.T) {
 				},
 			},
 		},
+		"user input '2' for search type, then type 'Organizations', then type 'id', then type '1'": {
+			userInputs: []string{"2", "Organizations", "id", "1"},
+			expectedResults: []data.OrganizationForDisplay{
+				data.OrganizationForDisplay{
+					Organization: *mock.MockOrganizations[0], UserNames: []string{mock.MockUsers[0].Name, mock.MockUsers[1].Name}, TicketIDs: []string{mock.MockTickets[0].ID, mock.MockTickets[1].ID},
+				},
+			},
+		},
 		"user input '2' for search type, then type '3', then type 'id', then type '1'": {
 			userInputs: []string{"2", "3", "id", "1"},
 			expectedResults: []data.OrganizationForDisplay{
@@ -197,9 +506,24 @@ func (s *mockInteractionServiceForSearch) GetUserInput() (isQuitCommand bool, in
 
 type mockDataServiceForSearch struct{}
 
-func (s *mockDataServiceForSearch) PrepareStructMap(tickets []*data.Ticket, users []*data.User, organizations []*data.Organization) (map[string]map[string]data.Field, error) {
+func (s *mockDataServiceForSearch) PrepareStructMap(dataset data.Dataset) (map[string]map[string]data.Field, error) {
 	return mock.MockStructMap, nil
 }
-func (s *mockDataServiceForSearch) LoadFile() (tickets []*data.Ticket, users []*data.User, organizations []*data.Organization, err error) {
+func (s *mockDataServiceForSearch) LoadFile() (dataset data.Dataset, err error) {
 	return
 }
+func (s *mockDataServiceForSearch) Validate(dataset data.Dataset) (*data.ValidationReport, error) {
+	return nil, nil
+}
+
+func (s *mockDataServiceForSearch) LoadSnapshot() (map[string]map[string]data.Field, *data.ValidationReport, error) {
+	return nil, nil, data.ErrSnapshotDisabled
+}
+
+func (s *mockDataServiceForSearch) SaveSnapshot(structMap map[string]map[string]data.Field, report *data.ValidationReport) error {
+	return data.ErrSnapshotDisabled
+}
+
+func (s *mockDataServiceForSearch) Fingerprints() ([]data.SourceFingerprint, error) {
+	return nil, nil
+}